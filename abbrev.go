@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"time"
+)
+
+// runAbbrev prints the timezones that used an abbreviation, either at
+// an instant or at any time. The returned value is the exit status.
+func runAbbrev(args []string) int {
+	flags := flag.NewFlagSet("abbrev", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 && flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator abbrev [-db file] {abbreviation} [time]\n")
+		return 2
+	}
+	abbrev := flags.Arg(0)
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	var uses []tzdb.AbbreviationUse
+	var err error
+	if flags.NArg() == 2 {
+		sec, perr := parseInstant(flags.Arg(1))
+		if perr != nil {
+			fmt.Fprintf(os.Stderr, "invalid time %q: %s\n", flags.Arg(1), perr)
+			return 2
+		}
+		uses, err = tzdb.GetByAbbreviation(abbrev, sec)
+	} else {
+		uses, err = tzdb.GetAbbreviationUses(abbrev)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read index of abbreviations: %s\n", err)
+		return 1
+	}
+
+	for _, use := range uses {
+		fmt.Printf("%-32s %+6d isdst=%-5v %s - %s\n", use.Timezone, use.Offset, use.IsDST,
+			formatPeriodEnd(use.Start), formatPeriodEnd(use.End))
+	}
+	fmt.Printf("\n%d periods\n", len(uses))
+	if len(uses) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// formatPeriodEnd formats the start or end of a period of use,
+// which may be the beginning or the end of time.
+func formatPeriodEnd(sec int64) string {
+	if sec == -1 || sec < -1<<50 {
+		return "..."
+	}
+	return time.Unix(sec, 0).UTC().Format("2006-01-02 15:04:05")
+}
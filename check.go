@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+)
+
+// checkReport is the machine-readable result of the check command.
+type checkReport struct {
+	Database string         `json:"database"`
+	OK       bool           `json:"ok"`
+	Problems []tzdb.Problem `json:"problems"`
+}
+
+// runCheck validates the structural consistency of a database.
+// The returned value is the exit status.
+func runCheck(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to check")
+	asJSON := flags.Bool("json", false, "print report in JSON format")
+	flags.Parse(args)
+
+	if err := tzdb.OpenRO(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 2
+	}
+	defer tzdb.Close()
+
+	problems, err := tzdb.Check()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot check %q: %s\n", *filename, err)
+		return 2
+	}
+
+	report := checkReport{Database: *filename, OK: len(problems) == 0, Problems: problems}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		for _, problem := range problems {
+			where := problem.Timezone
+			if problem.Table != "" && where != "" {
+				where = fmt.Sprintf("%s (%s)", where, problem.Table)
+			} else if problem.Table != "" {
+				where = problem.Table
+			}
+			fmt.Printf("%-22s %-40s %s\n", problem.Kind, where, problem.Detail)
+		}
+		fmt.Printf("\n%d problems found\n", len(problems))
+	}
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"strconv"
+)
+
+// runCountries lists all countries, or the timezones used in a country.
+// The returned value is the exit status.
+func runCountries(args []string) int {
+	flags := flag.NewFlagSet("countries", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator countries [-db file] [country code]\n")
+		return 2
+	}
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	if flags.NArg() == 0 {
+		countries, err := tzdb.GetCountries()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read countries: %s\n", err)
+			return 1
+		}
+		for _, country := range countries {
+			fmt.Printf("%s  %s\n", country.Code, country.Name)
+		}
+		fmt.Printf("\n%d countries\n", len(countries))
+		return 0
+	}
+
+	zones, err := tzdb.GetZonesByCountry(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read timezones: %s\n", err)
+		return 1
+	}
+	if len(zones) == 0 {
+		fmt.Fprintf(os.Stderr, "no timezones for country %q\n", flags.Arg(0))
+		return 1
+	}
+	for _, zone := range zones {
+		printCountryZone(zone)
+	}
+	return 0
+}
+
+// runNearest prints the timezone whose principal location is the nearest
+// to the specified coordinates. The returned value is the exit status.
+func runNearest(args []string) int {
+	flags := flag.NewFlagSet("nearest", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator nearest [-db file] {latitude} {longitude}\n")
+		fmt.Fprintf(os.Stderr, "coordinates are given in degrees, positive north and east (e.g. 37.98 23.73)\n")
+		return 2
+	}
+
+	var coords [2]float64
+	for i := range coords {
+		value, err := strconv.ParseFloat(flags.Arg(i), 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid coordinate %q\n", flags.Arg(i))
+			return 2
+		}
+		coords[i] = value
+	}
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	zone, distance, err := tzdb.NearestZone(coords[0], coords[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot find nearest timezone: %s\n", err)
+		return 1
+	}
+	printCountryZone(zone)
+	fmt.Printf("\n%.0f km away\n", distance)
+	return 0
+}
+
+// printCountryZone prints a timezone of a country on a single line.
+func printCountryZone(zone tzdb.CountryZone) {
+	fmt.Printf("%s  %-32s %+9.4f %+10.4f  %s\n", zone.Country, zone.Timezone, zone.Latitude, zone.Longitude, zone.Comment)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"math"
+)
+
+// dedupOptions configure the sharing of identical tables of zones.
+type dedupOptions struct {
+	enabled bool
+	since   string // compare zones since this instant, if any
+}
+
+// dedupFlags defines the flags that configure deduplication.
+func dedupFlags(flags *flag.FlagSet) *dedupOptions {
+	options := &dedupOptions{}
+	flags.BoolVar(&options.enabled, "dedup", false, "store identical sets of zones of originals once")
+	flags.StringVar(&options.since, "dedup-since", "", "only compare zones since this time (e.g. 1970-01-01T00:00:00Z)")
+	return options
+}
+
+// cutoff returns the instant zones are compared since.
+func (o dedupOptions) cutoff() (int64, error) {
+	if o.since == "" {
+		return math.MinInt64, nil
+	}
+	sec, err := parseInstant(o.since)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %s", o.since, err)
+	}
+	return sec, nil
+}
+
+// dedupZones points originals with identical zones to a single
+// table of zones and counts the outcome in the summary of the run.
+func dedupZones(options dedupOptions, rep reporter, summary *runSummary) error {
+	since, err := options.cutoff()
+	if err != nil {
+		return err
+	}
+
+	rep.stage("Deduplicating zones", 1)
+	stats, err := tzdb.DedupZones(since)
+	if err != nil {
+		return err
+	}
+	rep.progress(1, fmt.Sprintf("%d sets of zones shared", stats.Groups))
+
+	summary.Deduplicated = stats.Shared
+	summary.ZonesDropped = stats.Zones
+	summary.BytesSaved = stats.Bytes
+	return nil
+}
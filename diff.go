@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+)
+
+// Exit status of diff, following the convention of diff(1).
+const (
+	diffSame    = 0
+	diffFound   = 1
+	diffTrouble = 2
+)
+
+// runDiff compares two databases and prints the differences
+// grouped by timezone. The returned value is the exit status.
+func runDiff(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator diff {db_filename} {db_filename}\n")
+		return diffTrouble
+	}
+
+	diffs, err := tzdb.Diff(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff failed: %s\n", err)
+		return diffTrouble
+	}
+
+	if len(diffs) == 0 {
+		return diffSame
+	}
+
+	var current string
+	for _, diff := range diffs {
+		if diff.Timezone != current {
+			current = diff.Timezone
+			fmt.Printf("%s\n", current)
+		}
+		fmt.Printf("    %-8s < %s\n", diff.Kind, diff.A)
+		fmt.Printf("    %-8s > %s\n", "", diff.B)
+	}
+	fmt.Printf("\n%d differences found\n", len(diffs))
+
+	return diffFound
+}
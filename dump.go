@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdata"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default cutoff years of zdump.
+const (
+	zdumpLoYear = -500
+	zdumpHiYear = 2500
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// transition is a change of zone, in a form common to all sources.
+type transition struct {
+	when   int64
+	name   string
+	offset int64
+	isDST  bool
+}
+
+// runDump prints the transitions of the specified timezones in the
+// format of `zdump -v`, either from the database or from the zoneinfo
+// source. The returned value is the exit status.
+func runDump(args []string) int {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	source := flags.Bool("source", false, "read zoneinfo source instead of database")
+	cutoff := flags.String("c", "", "cutoff years as [loyear,]hiyear, as in zdump")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator dump [-db file | -source] [-c [loyear,]hiyear] {timezone} ...\n")
+		return 2
+	}
+
+	lo, hi, err := parseCutoff(*cutoff)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid cutoff %q: %s\n", *cutoff, err)
+		return 2
+	}
+
+	if !*source {
+		if err := openDB(*filename); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+			return 1
+		}
+		defer tzdb.Close()
+	}
+
+	// zdump aligns output on the longest name of timezone
+	width := 0
+	for _, timezone := range flags.Args() {
+		if len(timezone) > width {
+			width = len(timezone)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	status := 0
+	for _, timezone := range flags.Args() {
+		var before *transition
+		var trans []transition
+		if *source {
+			before, trans, err = sourceTransitions(timezone, hi)
+		} else {
+			before, trans, err = storedTransitions(timezone)
+		}
+		if err != nil {
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "cannot get transitions of %q: %s\n", timezone, err)
+			status = 1
+			continue
+		}
+		writeZdump(out, fmt.Sprintf("%-*s", width, timezone), before, trans, lo, hi)
+	}
+
+	return status
+}
+
+// parseCutoff converts the argument of -c to a range of instants.
+func parseCutoff(value string) (lo, hi int64, err error) {
+	loYear, hiYear := zdumpLoYear, zdumpHiYear
+
+	if value != "" {
+		years := strings.Split(value, ",")
+		if len(years) > 2 {
+			return 0, 0, fmt.Errorf("too many years")
+		}
+		if hiYear, err = strconv.Atoi(years[len(years)-1]); err != nil {
+			return 0, 0, err
+		}
+		if len(years) == 2 {
+			if loYear, err = strconv.Atoi(years[0]); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	lo = time.Date(loYear, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	hi = time.Date(hiYear, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+	return lo, hi, nil
+}
+
+// writeZdump prints transitions in [lo, hi) as `zdump -v` does.
+// For each transition, the last second of the previous zone and the
+// first second of the new one are printed. The zone before the first
+// transition is only printed if it is known.
+func writeZdump(w io.Writer, label string, before *transition, trans []transition, lo, hi int64) {
+	fmt.Fprintf(w, "%s  %d = NULL\n", label, int64(math.MinInt64))
+	fmt.Fprintf(w, "%s  %d = NULL\n", label, int64(math.MinInt64+secondsPerDay))
+
+	prev := before
+	for i := range trans {
+		cur := &trans[i]
+		// zdump only notices changes of name, offset or DST
+		if prev != nil && prev.name == cur.name && prev.offset == cur.offset && prev.isDST == cur.isDST {
+			continue
+		}
+		if cur.when >= lo && cur.when < hi {
+			if prev != nil {
+				writeZdumpLine(w, label, cur.when-1, prev)
+			}
+			writeZdumpLine(w, label, cur.when, cur)
+		}
+		prev = cur
+	}
+
+	fmt.Fprintf(w, "%s  %d = NULL\n", label, int64(math.MaxInt64-secondsPerDay))
+	fmt.Fprintf(w, "%s  %d = NULL\n", label, int64(math.MaxInt64))
+}
+
+func writeZdumpLine(w io.Writer, label string, sec int64, zone *transition) {
+	const layout = "Mon Jan _2 15:04:05 2006"
+
+	utc := time.Unix(sec, 0).UTC()
+	local := utc.Add(time.Duration(zone.offset) * time.Second)
+	isdst := 0
+	if zone.isDST {
+		isdst = 1
+	}
+	fmt.Fprintf(w, "%s  %s UT = %s %s isdst=%d gmtoff=%d\n",
+		label, utc.Format(layout), local.Format(layout), zone.name, isdst, zone.offset)
+}
+
+// storedTransitions converts the stored zones of a timezone to transitions.
+// The zone in effect before the first stored zone is not stored, so it is
+// reported as unknown.
+func storedTransitions(timezone string) (*transition, []transition, error) {
+	zones, err := tzdb.GetZones(timezone)
+	if err != nil {
+		// originals without any transitions never get a table of zones
+		original, orgErr := tzdb.GetOriginal(timezone)
+		if orgErr != nil || original.TabVer != 0 {
+			return nil, nil, err
+		}
+		return nil, nil, nil
+	}
+
+	trans := make([]transition, 0, len(zones))
+	for _, zone := range zones {
+		trans = append(trans, transition{when: zone.Start, name: zone.Name, offset: zone.Offset, isDST: zone.IsDST})
+	}
+
+	return nil, trans, nil
+}
+
+// sourceTransitions reads the transitions of a timezone from the zoneinfo
+// source. Transitions described by the footer (TZ string) of the timezone
+// file are calculated up to the specified instant.
+func sourceTransitions(timezone string, hi int64) (*transition, []transition, error) {
+	data, err := tzdata.GetData(timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	first := data.FirstEra()
+	before := &transition{name: first.Name, offset: int64(first.Offset), isDST: first.IsDST}
+
+	trans := make([]transition, 0, len(data.Trans))
+	for _, tx := range data.Trans {
+		if tx.Index != 255 {
+			era := data.Eras[tx.Index]
+			trans = append(trans, transition{when: tx.When, name: era.Name, offset: int64(era.Offset), isDST: era.IsDST})
+		} else {
+			trans = append(trans, transition{when: tx.When, name: tx.AltName, offset: int64(tx.AltOffset), isDST: isDSTEra(data, tx.AltName, tx.AltOffset)})
+		}
+	}
+
+	if len(trans) == 0 || data.Extend == "" {
+		return before, trans, nil
+	}
+
+	// walk through the eras described by the footer
+	sec := trans[len(trans)-1].when
+	for {
+		_, _, _, end := data.Lookup(sec)
+		if end <= sec {
+			// eras calculated from the footer may end where they start
+			end = sec + secondsPerDay
+		}
+		if end >= hi || end == math.MaxInt64 {
+			break
+		}
+		name, offset, _, _ := data.Lookup(end)
+		last := trans[len(trans)-1]
+		if name != last.name || int64(offset) != last.offset {
+			trans = append(trans, transition{when: end, name: name, offset: int64(offset), isDST: isDSTEra(data, name, offset)})
+		}
+		sec = end
+	}
+
+	return before, trans, nil
+}
+
+// isDSTEra reports whether the era with the specified name
+// and offset is marked as daylight savings time. Like tzdata
+// does for transitions calculated from the footer, the most
+// recently defined matching era is preferred.
+func isDSTEra(data *tzdata.TZdata, name string, offset int) bool {
+	for i := len(data.Eras) - 1; i >= 0; i-- {
+		if data.Eras[i].Name == name && data.Eras[i].Offset == offset {
+			return data.Eras[i].IsDST
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"io"
+	"os"
+)
+
+// runExport writes the data of a database, as seen by readers,
+// in JSON, CSV or NDJSON format. The returned value is the exit status.
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to export")
+	format := flags.String("format", "json", "output format: json, flat-json, csv or ndjson")
+	output := flags.String("o", "", "output file (directory for csv), instead of standard output")
+	pattern := flags.String("tz", "", "export only timezones matching pattern (e.g. 'Europe/*')")
+	from := flags.String("from", "", "leave out zones that end before this instant")
+	to := flags.String("to", "", "leave out zones that start after this instant")
+	flags.Parse(args)
+
+	filter := tzdb.AllData
+	filter.Pattern = *pattern
+	for _, bound := range []struct {
+		value string
+		sec   *int64
+	}{{*from, &filter.From}, {*to, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		sec, err := parseInstant(bound.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid instant %q\n", bound.value)
+			return 2
+		}
+		*bound.sec = sec
+	}
+
+	var write func(w io.Writer, e *tzdb.Export) error
+	switch *format {
+	case "json":
+		write = func(w io.Writer, e *tzdb.Export) error { return e.WriteJSON(w, false) }
+	case "flat-json":
+		write = func(w io.Writer, e *tzdb.Export) error { return e.WriteJSON(w, true) }
+	case "ndjson":
+		write = func(w io.Writer, e *tzdb.Export) error { return e.WriteNDJSON(w) }
+	case "csv":
+		if *output == "" {
+			fmt.Fprintf(os.Stderr, "csv format needs an output directory (-o)\n")
+			return 2
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q (expected json, flat-json, csv or ndjson)\n", *format)
+		return 2
+	}
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	export, err := tzdb.ExportData(filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot export %q: %s\n", *filename, err)
+		return 1
+	}
+
+	if *format == "csv" {
+		err = export.WriteCSV(*output)
+	} else {
+		err = writeOutput(*output, func(w io.Writer) error { return write(w, export) })
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot write export: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeOutput writes to the specified file or to
+// the standard output, if no file is specified.
+func writeOutput(filename string, write func(w io.Writer) error) error {
+	file := os.Stdout
+	if filename != "" {
+		var err error
+		if file, err = os.Create(filename); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if filename != "" {
+		return file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,561 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzbin"
+	"github.com/pvar/ts-db-generator/tzdata"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runGenerate creates or updates a database with the timezones
+// found in the zoneinfo source of the system.
+// The returned value is the exit status.
+func runGenerate(args []string) int {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to create or update")
+	output := flags.String("output", "auto", "progress output: auto, tty, plain or json")
+	summaryFile := flags.String("summary", "", "also write JSON summary of run to file")
+	zoneinfo := flags.String("zoneinfo", tzdata.DefaultSourcePath, "directory of zoneinfo source")
+	imageFile := flags.String("image", "", "also write image of database to file, for embedding in Go binaries")
+	binaryFile := flags.String("binary", "", "also write database to file in compact binary format (tzbin)")
+	overridesFile := flags.String("overrides", "", "file of overrides to apply on top of tzdata")
+	since := flags.String("since", "", "drop zones before this time (e.g. 1900-01-01T00:00:00Z)")
+	policy := policyFlags(flags)
+	dedup := dedupFlags(flags)
+	flags.Parse(args)
+
+	tzdata.SetSourcePath(*zoneinfo)
+
+	rep, err := newReporter(*output, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 2
+	}
+
+	summary := generate(*filename, *policy, *overridesFile, *since, *dedup, rep)
+	rep.summary(summary)
+
+	if *summaryFile != "" {
+		if err := writeSummary(*summaryFile, summary); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot write summary: %s\n", err)
+			return 1
+		}
+	}
+
+	if summary.Status != "ok" {
+		return 1
+	}
+
+	if *imageFile != "" {
+		if err := writeImage(*filename, *imageFile); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot write image: %s\n", err)
+			return 1
+		}
+	}
+	if *binaryFile != "" {
+		if err := writeBinary(*filename, *binaryFile); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot write binary file: %s\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// writeImage stores an image of a database in a file,
+// which can be embedded and opened with tzdb.OpenFS.
+func writeImage(filename, imageFile string) error {
+	if err := openDB(filename); err != nil {
+		return err
+	}
+	defer tzdb.Close()
+
+	file, err := os.Create(imageFile)
+	if err != nil {
+		return err
+	}
+
+	if err := tzdb.WriteImage(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// safetyPolicy limits how much a single run may change the database.
+// Larger changes most probably mean that the zoneinfo source is broken.
+type safetyPolicy struct {
+	maxGrowth float64 // max ratio of new entries to stored entries
+}
+
+// policyFlags defines the flags that configure the safety policy.
+func policyFlags(flags *flag.FlagSet) *safetyPolicy {
+	policy := &safetyPolicy{}
+	flags.Float64Var(&policy.maxGrowth, "max-growth", 0.05, "max ratio of new originals, replicas or zones to stored ones")
+	return policy
+}
+
+// exceeds checks whether growing from stored to fresh entries violates the policy.
+func (p safetyPolicy) exceeds(fresh, stored int) bool {
+	return float64(fresh-stored)/float64(stored) > p.maxGrowth
+}
+
+// generate runs the whole update procedure on the specified
+// database and returns a summary of the run. Overrides are read
+// from the specified file, if any, on every run. Zones before
+// since, if given, are dropped.
+func generate(filename string, policy safetyPolicy, overridesFile, since string, dedup dedupOptions, rep reporter) (summary runSummary) {
+	startTime := time.Now()
+	summary.Database = filename
+	summary.Status = "failed"
+	defer func() {
+		summary.Duration = time.Since(startTime).Seconds()
+	}()
+
+	fail := func(err error) runSummary {
+		rep.failure(err)
+		summary.Error = err.Error()
+		return summary
+	}
+
+	version, timezones, err := tzdata.GetList()
+	if err != nil {
+		return fail(fmt.Errorf("cannot load timezone metadata (tzdata.zi): %s", err))
+	}
+	summary.TZDataVer = version
+
+	clip := int64(math.MinInt64)
+	if since != "" {
+		if clip, err = parseInstant(since); err != nil {
+			return fail(fmt.Errorf("invalid time %q: %s", since, err))
+		}
+	}
+
+	var overrides []*override
+	if overridesFile != "" {
+		if overrides, err = readOverrides(overridesFile); err != nil {
+			return fail(fmt.Errorf("cannot read overrides %q: %s", overridesFile, err))
+		}
+		if abs, err := filepath.Abs(overridesFile); err == nil {
+			overridesFile = abs
+		}
+	}
+
+	originals := make(map[string]*tzdb.Original)
+	replicas := make(map[string][]string)
+	for replica, original := range timezones {
+		if originals[original] == nil {
+			originals[original] = &tzdb.Original{Name: original}
+		}
+		replicas[original] = append(replicas[original], replica)
+	}
+	summary.Originals = len(originals)
+	// timezones maps every original to itself as well
+	summary.Replicas = len(timezones) - len(originals)
+
+	if err := tzdb.Open(filename); err != nil {
+		return fail(fmt.Errorf("cannot open %q: %s", filename, err))
+	}
+	defer tzdb.Close()
+
+	// overrides dropped from the file are still recorded in the database
+	patches, err := newOverrideSet(overridesFile, overrides, timezones)
+	if err != nil {
+		return fail(fmt.Errorf("cannot load overrides: %s", err))
+	}
+
+	if err := storeOriginals(originals, policy, rep); err != nil {
+		return fail(fmt.Errorf("failed while storing originals: %s", err))
+	}
+
+	if err := storeReplicas(replicas, policy, rep); err != nil {
+		return fail(fmt.Errorf("failed while storing replicas: %s", err))
+	}
+
+	if err := storeCountryZones(timezones, rep); err != nil {
+		return fail(fmt.Errorf("failed while storing countries: %s", err))
+	}
+
+	if err := updateOriginals(version, originals, patches, clip, policy, rep, &summary); err != nil {
+		return fail(fmt.Errorf("failed while updating originals: %s", err))
+	}
+
+	if dedup.enabled {
+		if err := dedupZones(dedup, rep, &summary); err != nil {
+			return fail(fmt.Errorf("failed while deduplicating zones: %s", err))
+		}
+	}
+
+	if err := indexAbbreviations(summary.Updated+summary.Deduplicated, rep); err != nil {
+		return fail(fmt.Errorf("failed while indexing abbreviations: %s", err))
+	}
+
+	summary.Status = "ok"
+	return summary
+}
+
+// storeOriginals add new entries in the table of original timezones
+// THe ID of each entry is saved in the struct representing each
+// timezone, since it will be needed later-on, while storing the
+// replicas (links to originals).
+func storeOriginals(originals map[string]*tzdb.Original, policy safetyPolicy, rep reporter) error {
+	storedCount, err := tzdb.GetOriginalCount()
+	if err != nil || storedCount == 0 {
+		// if no original timezones present,
+		// assign a value that will in effect
+		// disable the following check...
+		storedCount = 123456789
+	}
+
+	// check if ammount of new originals supersedes the allowed share of stored originals
+	if policy.exceeds(len(originals), storedCount) {
+		return fmt.Errorf("updated set of originals contains too many new entries")
+	}
+
+	rep.stage("Adding original timezone", len(originals))
+	i := 0
+	for org, _ := range originals {
+		i++
+		rep.progress(i, org)
+
+		id, err := tzdb.AddOriginal(org)
+		if err != nil {
+			return fmt.Errorf("attempt to add %q failed with: %s", org, err)
+		}
+		originals[org].ID = id
+	}
+	return nil
+}
+
+// storeReplicas stores groups of replica-timezones.
+// That is, timezones that are linked to another timezone
+// and refer to the same set of data.
+func storeReplicas(replicas map[string][]string, policy safetyPolicy, rep reporter) error {
+	storedCount, err := tzdb.GetReplicaCount()
+	if err != nil || storedCount == 0 {
+		// if no original timezones present,
+		// assign a value that will in effect
+		// disable the following check...
+		storedCount = 123456789
+	}
+
+	// check if ammount of new replicas supersedes the allowed share of stored replicas
+	if policy.exceeds(len(replicas), storedCount) {
+		return fmt.Errorf("updated set of replicas contains too many new entries")
+	}
+
+	rep.stage("Adding group of replicas", len(replicas))
+	i := 0
+	for org, rlist := range replicas {
+		i++
+		rep.progress(i, org)
+
+		err := tzdb.AddReplicas(rlist, org)
+		if err != nil {
+			return fmt.Errorf("attempt to add %q failed with: %s", org, err)
+		}
+	}
+	return nil
+}
+
+// storeCountryZones stores the countries each timezone is used in, as listed
+// in zone.tab and zone1970.tab, along with the names of countries. Entries
+// of zone.tab come first, since their comments are specific to each country.
+// Tables missing from the source are reported, but do not abort the update.
+func storeCountryZones(timezones map[string]string, rep reporter) error {
+	names, err := tzdata.GetCountries()
+	if err != nil {
+		rep.warning("iso3166.tab", fmt.Sprintf("countries not updated: %s", err))
+		return nil
+	}
+
+	entries := make([]tzdata.TabEntry, 0, 1000)
+	for _, tab := range []string{"zone.tab", "zone1970.tab"} {
+		tabEntries, err := tzdata.GetZoneTab(tab)
+		if err != nil {
+			rep.warning(tab, fmt.Sprintf("countries not updated: %s", err))
+			return nil
+		}
+		entries = append(entries, tabEntries...)
+	}
+
+	rep.stage("Adding countries of timezones", len(entries))
+	zones := make([]tzdb.CountryZone, 0, len(entries))
+	listed := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		rep.progress(i+1, entry.Timezone)
+
+		if _, ok := timezones[entry.Timezone]; !ok {
+			rep.warning(entry.Timezone, "listed in zone tables but unknown to tzdata.zi")
+			continue
+		}
+		for _, country := range entry.Countries {
+			if listed[country+entry.Timezone] {
+				continue
+			}
+			listed[country+entry.Timezone] = true
+			zones = append(zones, tzdb.CountryZone{Timezone: entry.Timezone, Country: country,
+				Latitude: entry.Latitude, Longitude: entry.Longitude, Comment: entry.Comment})
+		}
+	}
+
+	countries := make([]tzdb.Country, 0, len(names))
+	for code, name := range names {
+		countries = append(countries, tzdb.Country{Code: code, Name: name})
+	}
+
+	return tzdb.SetCountryZones(countries, zones)
+}
+
+// updateOriginals stores all related to each original timezone.
+// That is, all the available zones, the default zone and offset
+// and the version of the tzdata set used. Data are patched with
+// overrides, if any, and clipped to the specified instant, which
+// is recorded in the original if any zones were dropped. The outcome
+// for each original is counted in the summary of the run.
+func updateOriginals(ver string, originals map[string]*tzdb.Original, patches *overrideSet, since int64, policy safetyPolicy, rep reporter, summary *runSummary) error {
+	nowTime := time.Now().Unix()
+
+	// loop through original timezones...
+	rep.stage("Adding full data of original timezone", len(originals))
+	i := 0
+	for org, _ := range originals {
+		i++
+		rep.progress(i, org)
+
+		// get data related to selected timezone
+		data, err := tzdata.GetData(org)
+		if err != nil {
+			return fmt.Errorf("failed to get data for timezone %q: %s", org, err)
+		}
+
+		data, statuses, err := patches.apply(org, data)
+		if err != nil {
+			return err
+		}
+		for i, status := range statuses {
+			if status == "superseded" {
+				id := patches.byOriginal[org][i].ID
+				rep.warning(org, fmt.Sprintf("override %q is superseded by tzdata %s and can be removed", id, ver))
+				summary.Superseded++
+			} else {
+				summary.Overridden++
+			}
+		}
+
+		originals[org].TZDVer = ver
+		originals[org].Footer = data.Extend
+		originals[org].Clipped = data.Clip(since)
+		originals[org].Since = 0
+		if originals[org].Clipped {
+			originals[org].Since = since
+		}
+
+		// These are the defualt values for Zone name (abbreviation)
+		// and offset. They will be ignored if there are any zones
+		// defined...
+		zoneName, offset, _, _ := data.Lookup(nowTime)
+		originals[org].DZone = zoneName
+		originals[org].DOffset = int64(offset)
+
+		// get metadata for already stored table of zones
+		curTableVer, storedZones, storedTZdataVer, err := tzdb.GetZoneTableMeta(int(originals[org].ID))
+		if err != nil {
+			// if no stored zones are present,
+			// assign a value that will in effect
+			// disable the following check...
+			storedZones = 123456789
+		}
+
+		saveZones := false
+		zoneCount := len(data.Trans)
+
+		// If frershly parsed data are of an older version than the stored data,
+		// abort the update proceedure immediately.
+		if ver < storedTZdataVer {
+			return fmt.Errorf("parsed TZdata are of an older version (%s < %s)", ver, storedTZdataVer)
+		}
+
+		// Footers were not stored by earlier versions of the generator.
+		storedFooter := ""
+		sameClipping := !originals[org].Clipped
+		parsedZones := zoneCount
+		if stored, err := tzdb.GetOriginalByName(org); err == nil {
+			storedFooter = stored.Footer
+			sameClipping = stored.Clipped == originals[org].Clipped && stored.Since == originals[org].Since
+			// The zones of originals pointed to the table of another
+			// one by deduplication may only differ before the cut-off,
+			// so only the zones in effect since then are counted.
+			if stored.Shared {
+				if effective, err := tzdb.GetEffectiveZones(org); err == nil {
+					parsedZones = parsedZonesSince(data, stored.SharedSince)
+					storedZones = len(effective)
+				}
+			}
+		}
+
+		// Nor were the standard time and UT indicators of zones.
+		storedIndicators := true
+		if hasIndicators, err := tzdb.ZoneTableHasIndicators(int(originals[org].ID)); err == nil {
+			storedIndicators = hasIndicators
+		}
+
+		// If freshly parsed and stored data are of the same version
+		// AND the ammount of new zones equals the ammount stored ones
+		// AND the footer is the same AND so are the overrides applied
+		// AND the clipping AND the stored zones have indicators,
+		// there is nothing new to add.
+		if (ver == storedTZdataVer) && (parsedZones == storedZones) && (data.Extend == storedFooter) && !patches.changed(org) && sameClipping && storedIndicators {
+			// Nothing new to add!
+			// Proceed to next original timezone.
+			summary.Unchanged++
+			continue
+		}
+
+		// Check if ammount of new zones supersedes the allowed share of stored zones.
+		// Zones dropped by an earlier clipping are not new.
+		if sameClipping && policy.exceeds(parsedZones, storedZones) {
+			// Updated set of zones contains too many new entries!
+			// Proceed to next original timezone.
+			rep.warning(org, fmt.Sprintf("skipped, %d zones parsed while %d are stored", parsedZones, storedZones))
+			summary.Skipped++
+			continue
+		}
+
+		// if any zones are defined, populate slice of zones
+		zones := make([]tzdb.Zone, 0, zoneCount)
+		if zoneCount != 0 {
+			saveZones = true
+			zone := tzdb.Zone{}
+			for z := 0; z < zoneCount; z++ {
+				zone.Name = data.Eras[data.Trans[z].Index].Name
+				zone.Offset = int64(data.Eras[data.Trans[z].Index].Offset)
+				zone.IsDST = data.Eras[data.Trans[z].Index].IsDST
+				zone.IsStd = data.Eras[data.Trans[z].Index].IsStd
+				zone.IsUT = data.Eras[data.Trans[z].Index].IsUT
+				zone.Start = data.Trans[z].When
+				if z+1 < zoneCount {
+					zone.End = data.Trans[z+1].When - 1
+				} else {
+					zone.End = -1 // end of time!
+				}
+				zones = append(zones, zone)
+			}
+
+			originals[org].TabVer = int64(curTableVer + 1)
+			// name of zones-table will be auto-generated
+		}
+
+		// Save updated state of original timezone.
+		if err := tzdb.UpdateOriginal(originals[org]); err != nil {
+			return fmt.Errorf("attempt to update original %q failed with: %s", org, err)
+		}
+
+		// If new zones are to be saved... do it!
+		if saveZones {
+			if err := tzdb.AddZones(org, zones); err != nil {
+				return fmt.Errorf("attempt to add zones for original %q failed with: %s", org, err)
+			}
+		}
+
+		if err := patches.record(org, statuses, ver); err != nil {
+			return fmt.Errorf("attempt to record overrides of original %q failed with: %s", org, err)
+		}
+		summary.Updated++
+	}
+	return nil
+}
+
+// parsedZonesSince counts the parsed zones in effect since an instant,
+// as tzdb.GetEffectiveZones returns those of a table shared since then.
+func parsedZonesSince(data *tzdata.TZdata, since int64) int {
+	count := 0
+	for i := range data.Trans {
+		if i+1 == len(data.Trans) || data.Trans[i+1].When > since {
+			count++
+		}
+	}
+	return count
+}
+
+// indexAbbreviations rebuilds the index of abbreviations, if any
+// original was updated or pointed to another table of zones, or
+// the database has no index yet.
+func indexAbbreviations(updated int, rep reporter) error {
+	count, err := tzdb.GetAbbreviationCount()
+	if err != nil {
+		return err
+	}
+	if updated == 0 && count != 0 {
+		return nil
+	}
+
+	rep.stage("Indexing abbreviations", 1)
+	count, err = tzdb.RebuildAbbreviations()
+	if err != nil {
+		return err
+	}
+	rep.progress(1, fmt.Sprintf("%d periods", count))
+	return nil
+}
+
+// writeBinary stores the data of a database, as seen by readers,
+// in a file of compact binary format, to be read with tzbin.
+func writeBinary(filename, binaryFile string) error {
+	if err := openDB(filename); err != nil {
+		return err
+	}
+	defer tzdb.Close()
+
+	version, err := tzdb.GetTZDataVersion()
+	if err != nil {
+		return err
+	}
+
+	snap, err := tzdb.LoadSnapshot()
+	if err != nil {
+		return err
+	}
+
+	bin := &tzbin.Snapshot{
+		TZDataVersion: version,
+		Originals:     make([]tzbin.Original, 0, len(snap.Originals)),
+		Replicas:      snap.Replicas,
+		Zones:         make(map[string][]tzbin.Zone, len(snap.Zones))}
+	for name, original := range snap.Originals {
+		bin.Originals = append(bin.Originals, tzbin.Original{Name: name, DZone: original.DZone,
+			DOffset: original.DOffset, TZDVer: original.TZDVer, Footer: original.Footer,
+			Clipped: original.Clipped, Since: original.Since})
+	}
+	for name, zones := range snap.Zones {
+		binZones := make([]tzbin.Zone, len(zones))
+		for i, zone := range zones {
+			binZones[i] = tzbin.Zone{Name: zone.Name, Start: zone.Start, End: zone.End,
+				Offset: zone.Offset, IsDST: zone.IsDST, IsStd: zone.IsStd, IsUT: zone.IsUT}
+		}
+		bin.Zones[name] = binZones
+	}
+
+	file, err := os.Create(binaryFile)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = tzbin.Write(w, bin)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		// do not leave a partial file behind
+		file.Close()
+		os.Remove(binaryFile)
+		return err
+	}
+	return file.Close()
+}
@@ -1,5 +1,5 @@
 module github.com/pvar/ts-db-generator
 
-go 1.15
+go 1.16
 
 require github.com/mattn/go-sqlite3 v1.14.4
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runImport patches a database with data of an export, which may have been
+// edited. Affected originals get a new table of zones, like they would with
+// an update from tzdata, and an audit entry. The returned value is the exit status.
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to patch")
+	dryRun := flags.Bool("n", false, "only report what would be changed")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator import [-db file] [-n] {export file or csv directory}\n")
+		return 2
+	}
+	source := flags.Arg(0)
+
+	export, err := readExport(source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read %q: %s\n", source, err)
+		return 1
+	}
+
+	// dry runs leave the file alone, not even adding missing tables
+	open := tzdb.Open
+	if *dryRun {
+		open = openDB
+	}
+	if err := open(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	problems, err := tzdb.CheckImport(export)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot check %q: %s\n", source, err)
+		return 1
+	}
+	if len(problems) != 0 {
+		for _, p := range problems {
+			fmt.Printf("%-32s %-20s %s\n", p.Timezone, p.Kind, p.Detail)
+		}
+		fmt.Printf("\n%d problems found, nothing imported\n", len(problems))
+		return 1
+	}
+
+	if abs, err := filepath.Abs(source); err == nil {
+		source = abs
+	}
+
+	changes := make([]tzdb.ImportChange, 0)
+	for i := range export.Timezones {
+		tz := &export.Timezones[i]
+		change, err := importTimezone(tz, source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot import %q: %s\n", tz.Name, err)
+			return 1
+		}
+		if change != nil {
+			changes = append(changes, *change)
+			fmt.Printf("%-32s %s\n", tz.Name, change.Audit.Detail)
+		}
+	}
+	updated := len(changes)
+
+	if updated != 0 && !*dryRun {
+		// nothing is stored unless all changes are
+		if err := tzdb.ApplyImport(changes); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot import %q, nothing imported: %s\n", source, err)
+			return 1
+		}
+		if _, err := tzdb.RebuildAbbreviations(); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot index abbreviations: %s\n", err)
+			return 1
+		}
+	}
+
+	if *dryRun {
+		fmt.Printf("\n%d originals would be updated, %d unchanged\n", updated, len(export.Timezones)-updated)
+	} else {
+		fmt.Printf("\n%d originals updated, %d unchanged\n", updated, len(export.Timezones)-updated)
+	}
+	return 0
+}
+
+// readExport reads an export of any format: a directory is
+// expected to hold CSV files, while a file holds JSON data.
+func readExport(source string) (*tzdb.Export, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return tzdb.ReadExportCSV(source)
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return tzdb.ReadExport(file)
+}
+
+// importTimezone compares an exported timezone with the stored one and
+// returns the change needed to store it, or nil if they do not differ.
+// Exported replicas are ignored.
+func importTimezone(tz *tzdb.ExportedTimezone, source string) (*tzdb.ImportChange, error) {
+	original, err := tzdb.GetOriginalByName(tz.Name)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := tzdb.GetZones(tz.Name)
+	if err != nil {
+		stored = nil
+	}
+	zones := tz.ImportedZones()
+
+	sameZones := len(zones) == len(stored)
+	for i := 0; sameZones && i < len(zones); i++ {
+		a, b := zones[i], stored[i]
+		sameZones = a.Name == b.Name && a.Start == b.Start && a.End == b.End && a.Offset == b.Offset && a.IsDST == b.IsDST &&
+			a.IsStd == b.IsStd && a.IsUT == b.IsUT
+	}
+	clipped := tz.ClippedSince != nil
+	sameClipping := original.Clipped == clipped && (!clipped || original.Since == *tz.ClippedSince)
+	if sameZones && sameClipping && original.DZone == tz.DefaultZone && original.DOffset == tz.DefaultOffset &&
+		original.Footer == tz.Footer && original.TZDVer == tz.TZDataVersion {
+		return nil, nil
+	}
+
+	detail := fmt.Sprintf("%d zones (%d stored), footer %q, tzdata %s", len(zones), len(stored), tz.Footer, tz.TZDataVersion)
+	if sameZones {
+		detail = fmt.Sprintf("same zones, default %s %+d, footer %q, tzdata %s", tz.DefaultZone, tz.DefaultOffset, tz.Footer, tz.TZDataVersion)
+	}
+
+	original.DZone = tz.DefaultZone
+	original.DOffset = tz.DefaultOffset
+	original.Footer = tz.Footer
+	original.TZDVer = tz.TZDataVersion
+	original.Clipped = clipped
+	original.Since = 0
+	if clipped {
+		original.Since = *tz.ClippedSince
+		detail += fmt.Sprintf(", clipped since %d", original.Since)
+	}
+
+	// originals sharing the table of another one get their own
+	// table, since updated originals point to a table of their own
+	change := &tzdb.ImportChange{Original: original}
+	if (!sameZones || original.SharesZones()) && len(zones) != 0 {
+		original.TabVer++
+		detail += fmt.Sprintf(", table version %d", original.TabVer)
+		change.Zones = zones
+	}
+
+	change.Audit = tzdb.AuditEntry{Time: time.Now().Unix(), Action: "import", Source: source, Timezone: tz.Name, Detail: detail}
+	return change, nil
+}
+
+// runAudit prints the audit entries of a database.
+// The returned value is the exit status.
+func runAudit(args []string) int {
+	flags := flag.NewFlagSet("audit", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	flags.Parse(args)
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	entries, err := tzdb.GetAudit()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read audit entries: %s\n", err)
+		return 1
+	}
+
+	for _, e := range entries {
+		fmt.Printf("%s  %-8s %-32s %s\n    source: %s\n", time.Unix(e.Time, 0).UTC().Format(time.RFC3339),
+			e.Action, e.Timezone, e.Detail, e.Source)
+	}
+	fmt.Printf("\n%d entries\n", len(entries))
+	return 0
+}
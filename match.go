@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"strings"
+	"time"
+)
+
+// runMatch prints the timezones matching a set of timestamps with offsets.
+// The returned value is the exit status.
+func runMatch(args []string) int {
+	flags := flag.NewFlagSet("match", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	country := flags.String("country", "", "country to rank first (ISO 3166 code, e.g. GR)")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator match [-db file] [-country code] {observation} ...\n")
+		fmt.Fprintf(os.Stderr, "observations are RFC 3339 timestamps, optionally with an abbreviation (e.g. 2020-07-01T12:00:00+03:00/EEST)\n")
+		return 2
+	}
+
+	observations := make([]tzdb.Observation, 0, flags.NArg())
+	for _, arg := range flags.Args() {
+		o, err := parseObservation(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid observation %q: %s\n", arg, err)
+			return 2
+		}
+		observations = append(observations, o)
+	}
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	matches, err := tzdb.MatchOffsets(observations, *country)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot match offsets: %s\n", err)
+		return 1
+	}
+
+	for _, match := range matches {
+		fmt.Printf("%-32s %s\n", match.Timezone, strings.Join(match.Countries, ","))
+	}
+	fmt.Printf("\n%d timezones match\n", len(matches))
+	if len(matches) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// parseObservation converts a timestamp with an offset, in RFC 3339 format,
+// optionally followed by a slash and the abbreviation of the zone.
+func parseObservation(value string) (tzdb.Observation, error) {
+	var o tzdb.Observation
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		value, o.Abbrev = value[:i], value[i+1:]
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return o, err
+	}
+	_, offset := t.Zone()
+	o.At, o.Offset = t.Unix(), int64(offset)
+	return o, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runNames imports display names of timezones from CLDR files: the usage of
+// metazones (metaZones.xml or .json) and the names of each locale (main/{locale}.xml
+// or timeZoneNames.json). Stored data of the same kind, or of the same locale,
+// are replaced. The returned value is the exit status.
+func runNames(args []string) int {
+	flags := flag.NewFlagSet("names", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to update")
+	dryRun := flags.Bool("n", false, "only check the files")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator names [-db file] [-n] {CLDR file} ...\n")
+		return 2
+	}
+
+	all := make([]*tzdb.CLDRNames, 0, flags.NArg())
+	for _, source := range flags.Args() {
+		file, err := os.Open(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read %q: %s\n", source, err)
+			return 1
+		}
+		names, err := tzdb.ReadCLDRNames(file)
+		file.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot read %q: %s\n", source, err)
+			return 1
+		}
+		all = append(all, names)
+	}
+
+	if *dryRun {
+		for i, names := range all {
+			fmt.Printf("%-40s %s\n", flags.Arg(i), describeNames(names))
+		}
+		fmt.Printf("\n%d files checked, nothing imported\n", len(all))
+		return 0
+	}
+
+	if err := tzdb.Open(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	for i, names := range all {
+		source := flags.Arg(i)
+		if len(names.Metazones) != 0 {
+			if err := tzdb.SetMetazones(names.Metazones); err != nil {
+				fmt.Fprintf(os.Stderr, "cannot store metazones of %q: %s\n", source, err)
+				return 1
+			}
+		}
+		if len(names.Names) != 0 {
+			if err := tzdb.SetZoneNames(names.Locale, names.Names); err != nil {
+				fmt.Fprintf(os.Stderr, "cannot store names of %q: %s\n", source, err)
+				return 1
+			}
+		}
+
+		if abs, err := filepath.Abs(source); err == nil {
+			source = abs
+		}
+		audit := tzdb.AuditEntry{Time: time.Now().Unix(), Action: "names", Source: source, Detail: describeNames(names)}
+		if err := tzdb.AddAudit(audit); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot record audit entry: %s\n", err)
+			return 1
+		}
+		fmt.Printf("%-40s %s\n", flags.Arg(i), audit.Detail)
+	}
+	return 0
+}
+
+// describeNames summarizes the content of a CLDR file.
+func describeNames(names *tzdb.CLDRNames) string {
+	if len(names.Names) == 0 {
+		return fmt.Sprintf("%d usages of metazones", len(names.Metazones))
+	}
+	return fmt.Sprintf("%d names of locale %s", len(names.Names), names.Locale)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdata"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"strconv"
+	"time"
+)
+
+// overrideFile is the content of an overrides file.
+type overrideFile struct {
+	Overrides []*override `json:"overrides"`
+}
+
+// override patches the data of a timezone, as parsed from tzdata,
+// e.g. to apply a change announced on short notice, before a
+// release of tzdata has it. The rule, if any, is applied first.
+type override struct {
+	ID          string               `json:"id"`
+	Timezone    string               `json:"timezone"`
+	Reason      string               `json:"reason,omitempty"`
+	Rule        string               `json:"rule,omitempty"` // TZ string, as in footers
+	From        string               `json:"from,omitempty"` // instant the rule applies from
+	Transitions []overrideTransition `json:"transitions,omitempty"`
+
+	from int64
+}
+
+// overrideTransition adds, replaces or removes a single transition.
+type overrideTransition struct {
+	At     string `json:"at"`
+	Abbrev string `json:"abbrev,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	IsDST  bool   `json:"is_dst,omitempty"`
+	Remove bool   `json:"remove,omitempty"`
+
+	at int64
+}
+
+// readOverrides reads and validates an overrides file.
+// Instants are given as in lookup, except for "now".
+func readOverrides(filename string) ([]*override, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file overrideFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	for _, o := range file.Overrides {
+		if o.ID == "" || o.Timezone == "" {
+			return nil, fmt.Errorf("override without id or timezone")
+		}
+		if ids[o.ID] {
+			return nil, fmt.Errorf("duplicate override %q", o.ID)
+		}
+		ids[o.ID] = true
+
+		if o.Rule == "" && len(o.Transitions) == 0 {
+			return nil, fmt.Errorf("override %q has neither rule nor transitions", o.ID)
+		}
+		if o.Rule != "" {
+			if o.from, err = overrideInstant(o.From); err != nil {
+				return nil, fmt.Errorf("override %q: bad instant %q for rule", o.ID, o.From)
+			}
+		}
+		for i := range o.Transitions {
+			tx := &o.Transitions[i]
+			if tx.at, err = overrideInstant(tx.At); err != nil {
+				return nil, fmt.Errorf("override %q: bad instant %q of transition", o.ID, tx.At)
+			}
+			if !tx.Remove && tx.Abbrev == "" {
+				return nil, fmt.Errorf("override %q: transition at %q without abbreviation", o.ID, tx.At)
+			}
+		}
+	}
+
+	return file.Overrides, nil
+}
+
+// overrideInstant parses an instant of an override. Unlike lookup,
+// "now" is not accepted, so that overrides give the same result on every run.
+func overrideInstant(value string) (int64, error) {
+	if value == "now" {
+		return 0, strconv.ErrSyntax
+	}
+	return parseInstant(value)
+}
+
+// apply patches the data of a timezone.
+func (o *override) apply(data *tzdata.TZdata) error {
+	if o.Rule != "" {
+		if err := data.ReplaceRule(o.from, o.Rule); err != nil {
+			return err
+		}
+	}
+
+	for _, tx := range o.Transitions {
+		if tx.Remove {
+			if !data.RemoveTransition(tx.at) {
+				return fmt.Errorf("no transition at %s to remove", tx.At)
+			}
+			continue
+		}
+		era := tzdata.Era{Name: tx.Abbrev, Offset: tx.Offset, IsDST: tx.IsDST}
+		if err := data.SetTransition(tx.at, era); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// definition returns the override as recorded in the database.
+func (o *override) definition() string {
+	content, _ := json.Marshal(o)
+	return string(content)
+}
+
+// overrideSet holds the overrides of a run of the generator,
+// along with those recorded in the database by earlier runs.
+type overrideSet struct {
+	source     string                     // overrides file
+	byOriginal map[string][]*override     // overrides of each original
+	stored     map[string][]tzdb.Override // recorded overrides of each original
+}
+
+// newOverrideSet groups overrides by the original timezone they patch,
+// so that overrides may also refer to replicas.
+func newOverrideSet(source string, overrides []*override, timezones map[string]string) (*overrideSet, error) {
+	set := &overrideSet{
+		source:     source,
+		byOriginal: make(map[string][]*override),
+		stored:     make(map[string][]tzdb.Override)}
+
+	for _, o := range overrides {
+		original, ok := timezones[o.Timezone]
+		if !ok {
+			return nil, fmt.Errorf("override %q refers to unknown timezone %q", o.ID, o.Timezone)
+		}
+		set.byOriginal[original] = append(set.byOriginal[original], o)
+	}
+
+	stored, err := tzdb.GetOverrides()
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range stored {
+		set.stored[o.Timezone] = append(set.stored[o.Timezone], o)
+	}
+
+	return set, nil
+}
+
+// changed checks whether the overrides of an original differ from
+// the recorded ones, in which case the original has to be updated.
+func (s *overrideSet) changed(original string) bool {
+	overrides, stored := s.byOriginal[original], s.stored[original]
+	if len(overrides) != len(stored) {
+		return true
+	}
+
+	definitions := make(map[string]string, len(stored))
+	for _, o := range stored {
+		definitions[o.Name] = o.Definition
+	}
+	for _, o := range overrides {
+		if definitions[o.ID] != o.definition() {
+			return true
+		}
+	}
+	return false
+}
+
+// apply patches the data of an original with its overrides and returns the
+// status of each one. Overrides that do not change the data are superseded,
+// since tzdata already has the change.
+func (s *overrideSet) apply(original string, data *tzdata.TZdata) (*tzdata.TZdata, []string, error) {
+	overrides := s.byOriginal[original]
+	statuses := make([]string, len(overrides))
+	for i, o := range overrides {
+		patched := data.Clone()
+		if err := o.apply(patched); err != nil {
+			return nil, nil, fmt.Errorf("cannot apply override %q to %q: %s", o.ID, original, err)
+		}
+
+		statuses[i] = "applied"
+		if patched.Equal(data) {
+			statuses[i] = "superseded"
+		}
+		data = patched
+	}
+	return data, statuses, nil
+}
+
+// record stores the overrides of an original, along with their status, once
+// the original has been updated, and removes the ones dropped from the
+// overrides file. Changes are audited.
+func (s *overrideSet) record(original string, statuses []string, ver string) error {
+	now := time.Now().Unix()
+	stored := make(map[string]tzdb.Override)
+	for _, o := range s.stored[original] {
+		stored[o.Name] = o
+	}
+
+	for i, o := range s.byOriginal[original] {
+		definition := o.definition()
+		if old, ok := stored[o.ID]; !ok || old.Definition != definition {
+			detail := fmt.Sprintf("override %q", o.ID)
+			if o.Reason != "" {
+				detail += ": " + o.Reason
+			}
+			audit := tzdb.AuditEntry{Time: now, Action: "override", Source: s.source, Timezone: original, Detail: detail}
+			if err := tzdb.AddAudit(audit); err != nil {
+				return err
+			}
+		}
+		delete(stored, o.ID)
+
+		record := tzdb.Override{Name: o.ID, Timezone: original, Definition: definition,
+			TZDVer: ver, Status: statuses[i], Time: now}
+		if err := tzdb.SetOverride(record); err != nil {
+			return err
+		}
+	}
+
+	for name := range stored {
+		audit := tzdb.AuditEntry{Time: now, Action: "override-removed", Source: s.source, Timezone: original,
+			Detail: fmt.Sprintf("override %q", name)}
+		if err := tzdb.AddAudit(audit); err != nil {
+			return err
+		}
+		if err := tzdb.RemoveOverride(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// runSummary collects the outcome of a run of the generator.
+type runSummary struct {
+	Database     string  `json:"database"`
+	TZDataVer    string  `json:"tzdata_version"`
+	Originals    int     `json:"originals"`
+	Replicas     int     `json:"replicas"`
+	Updated      int     `json:"updated"`
+	Unchanged    int     `json:"unchanged"`
+	Skipped      int     `json:"skipped"`
+	Overridden   int     `json:"overridden"`              // overrides that changed the data
+	Superseded   int     `json:"superseded"`              // overrides that tzdata already has
+	Deduplicated int     `json:"deduplicated,omitempty"`  // originals pointed to a shared table of zones
+	ZonesDropped int     `json:"zones_dropped,omitempty"` // zones of the tables dropped by deduplication
+	BytesSaved   int64   `json:"bytes_saved,omitempty"`   // space saved by deduplication
+	Status       string  `json:"status"`                  // ok or failed
+	Error        string  `json:"error,omitempty"`
+	Duration     float64 `json:"duration_sec"`
+}
+
+// reporter receives the progress of the generator.
+// Stages are reported in order. Progress refers to the
+// last stage started. Warnings do not abort the run.
+type reporter interface {
+	stage(name string, total int)
+	progress(done int, item string)
+	warning(timezone, message string)
+	failure(err error)
+	summary(s runSummary)
+}
+
+// newReporter creates a reporter for the specified kind of output.
+// With "auto", progress is displayed in place when writing to a
+// terminal and in plain lines otherwise.
+func newReporter(output string, w *os.File) (reporter, error) {
+	switch output {
+	case "auto":
+		if isTerminal(w) {
+			return &ttyReporter{w: w}, nil
+		}
+		return &plainReporter{w: w}, nil
+	case "tty":
+		return &ttyReporter{w: w}, nil
+	case "plain":
+		return &plainReporter{w: w}, nil
+	case "json":
+		return &jsonReporter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown output %q (expected auto, tty, plain or json)", output)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeSummary stores the summary of a run in JSON format.
+func writeSummary(filename string, s runSummary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// printSummary prints the summary of a run for humans.
+func printSummary(w io.Writer, s runSummary) {
+	fmt.Fprintf(w, "Database  : %s (tzdata %s)\n", s.Database, s.TZDataVer)
+	fmt.Fprintf(w, "Timezones : %d originals, %d replicas\n", s.Originals, s.Replicas)
+	fmt.Fprintf(w, "Originals : %d updated, %d unchanged, %d skipped\n", s.Updated, s.Unchanged, s.Skipped)
+	if s.Overridden+s.Superseded != 0 {
+		fmt.Fprintf(w, "Overrides : %d applied, %d superseded\n", s.Overridden, s.Superseded)
+	}
+	if s.Deduplicated != 0 {
+		fmt.Fprintf(w, "Dedup     : %d originals share tables, %d zones dropped, %.1f KB saved\n",
+			s.Deduplicated, s.ZonesDropped, float64(s.BytesSaved)/1024)
+	}
+	if s.Status != "ok" {
+		fmt.Fprintf(w, "Failed    : %s\n", s.Error)
+		return
+	}
+	fmt.Fprintf(w, "All done in %.1fs. Have a nice day :)\n", s.Duration)
+}
+
+// ttyReporter displays progress in place, using ANSI escape codes.
+type ttyReporter struct {
+	w     io.Writer
+	name  string
+	total int
+}
+
+func (r *ttyReporter) stage(name string, total int) {
+	r.name, r.total = name, total
+	// save cursor position
+	fmt.Fprint(r.w, "\033[s")
+}
+
+func (r *ttyReporter) progress(done int, item string) {
+	// restore cursor position and clear line
+	fmt.Fprint(r.w, "\033[u\033[K")
+	fmt.Fprintf(r.w, "%s [%3d/%3d]", r.name, done, r.total)
+	if done == r.total {
+		fmt.Fprint(r.w, "\n")
+	}
+}
+
+func (r *ttyReporter) warning(timezone, message string) {
+	fmt.Fprintf(r.w, "\033[u\033[Kwarning: %s: %s\n\033[s", timezone, message)
+}
+
+func (r *ttyReporter) failure(err error) {
+	fmt.Fprintf(r.w, "\nerror: %s\n", err)
+}
+
+func (r *ttyReporter) summary(s runSummary) {
+	fmt.Fprint(r.w, "\n")
+	printSummary(r.w, s)
+}
+
+// plainReporter writes progress in plain lines, suitable for logs.
+// Progress of each stage is reported in steps of 10%.
+type plainReporter struct {
+	w     io.Writer
+	name  string
+	total int
+	step  int
+}
+
+func (r *plainReporter) stage(name string, total int) {
+	r.name, r.total, r.step = name, total, 0
+	fmt.Fprintf(r.w, "%s: %d entries\n", name, total)
+}
+
+func (r *plainReporter) progress(done int, item string) {
+	if r.total == 0 || done*10/r.total <= r.step {
+		return
+	}
+	r.step = done * 10 / r.total
+	fmt.Fprintf(r.w, "%s: %d/%d\n", r.name, done, r.total)
+}
+
+func (r *plainReporter) warning(timezone, message string) {
+	fmt.Fprintf(r.w, "warning: %s: %s\n", timezone, message)
+}
+
+func (r *plainReporter) failure(err error) {
+	fmt.Fprintf(r.w, "error: %s\n", err)
+}
+
+func (r *plainReporter) summary(s runSummary) {
+	printSummary(r.w, s)
+}
+
+// jsonReporter writes one JSON object per event (JSON lines).
+// The last line is the summary of the run.
+type jsonReporter struct {
+	encoder *json.Encoder
+	name    string
+	total   int
+}
+
+// jsonEvent is a single line of output of jsonReporter.
+type jsonEvent struct {
+	Time     string      `json:"time"`
+	Event    string      `json:"event"`
+	Stage    string      `json:"stage,omitempty"`
+	Done     int         `json:"done,omitempty"`
+	Total    int         `json:"total,omitempty"`
+	Timezone string      `json:"timezone,omitempty"`
+	Message  string      `json:"message,omitempty"`
+	Summary  *runSummary `json:"summary,omitempty"`
+}
+
+func (r *jsonReporter) emit(event jsonEvent) {
+	event.Time = time.Now().UTC().Format(time.RFC3339)
+	r.encoder.Encode(event)
+}
+
+func (r *jsonReporter) stage(name string, total int) {
+	r.name, r.total = name, total
+	r.emit(jsonEvent{Event: "stage", Stage: name, Total: total})
+}
+
+func (r *jsonReporter) progress(done int, item string) {
+	r.emit(jsonEvent{Event: "progress", Stage: r.name, Done: done, Total: r.total, Timezone: item})
+}
+
+func (r *jsonReporter) warning(timezone, message string) {
+	r.emit(jsonEvent{Event: "warning", Stage: r.name, Timezone: timezone, Message: message})
+}
+
+func (r *jsonReporter) failure(err error) {
+	r.emit(jsonEvent{Event: "error", Stage: r.name, Message: err.Error()})
+}
+
+func (r *jsonReporter) summary(s runSummary) {
+	r.emit(jsonEvent{Event: "summary", Summary: &s})
+}
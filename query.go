@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openDB opens the specified database in read-only mode and
+// makes sure it can actually be read, since OpenRO does not.
+func openDB(filename string) error {
+	if err := tzdb.OpenRO(filename); err != nil {
+		return err
+	}
+
+	if _, err := tzdb.GetOriginalCount(); err != nil {
+		tzdb.Close()
+		return err
+	}
+
+	return nil
+}
+
+// runList prints all original timezones, with the version of
+// their active table of zones and the version of tzdata used.
+// The returned value is the exit status.
+func runList(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	withReplicas := flags.Bool("replicas", false, "list replicas under each original")
+	flags.Parse(args)
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	originals, err := tzdb.GetOriginals()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read originals: %s\n", err)
+		return 1
+	}
+
+	replicas, err := tzdb.GetReplicas()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read replicas: %s\n", err)
+		return 1
+	}
+
+	linked := make(map[int64][]string, len(originals))
+	for _, replica := range replicas {
+		linked[replica.ProtoID] = append(linked[replica.ProtoID], replica.Name)
+	}
+
+	fmt.Printf("%-32s %-32s %5s %-8s %s\n", "ORIGINAL", "TABLE", "VER", "TZDATA", "REPLICAS")
+	for _, original := range originals {
+		fmt.Printf("%-32s %-32s %5d %-8s %d\n", original.Name, original.TabName,
+			original.TabVer, original.TZDVer, len(linked[original.ID]))
+		if !*withReplicas {
+			continue
+		}
+		for _, name := range linked[original.ID] {
+			if name != original.Name {
+				fmt.Printf("    %s\n", name)
+			}
+		}
+	}
+	fmt.Printf("\n%d originals, %d replicas\n", len(originals), len(replicas))
+
+	return 0
+}
+
+// runInfo prints the stored metadata of a timezone.
+// The returned value is the exit status.
+func runInfo(args []string) int {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator info [-db file] {timezone}\n")
+		return 2
+	}
+	timezone := flags.Arg(0)
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	original, err := tzdb.GetOriginal(timezone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot find timezone %q: %s\n", timezone, err)
+		return 1
+	}
+
+	replicas, err := tzdb.GetReplicas()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read replicas: %s\n", err)
+		return 1
+	}
+	names := make([]string, 0)
+	for _, replica := range replicas {
+		if replica.ProtoID == original.ID && replica.Name != original.Name {
+			names = append(names, replica.Name)
+		}
+	}
+
+	fmt.Printf("Timezone     : %s\n", timezone)
+	fmt.Printf("Original     : %s (ID %d)\n", original.Name, original.ID)
+	fmt.Printf("Replicas     : %s\n", strings.Join(names, ", "))
+	fmt.Printf("Default zone : %s %+d\n", original.DZone, original.DOffset)
+	fmt.Printf("TZdata       : %s\n", original.TZDVer)
+	fmt.Printf("Footer       : %s\n", original.Footer)
+	if original.Clipped {
+		fmt.Printf("History      : clipped, no zones before %s\n",
+			time.Unix(original.Since, 0).UTC().Format("2006-01-02 15:04:05 UT"))
+	}
+	if original.Shared {
+		fmt.Printf("History      : shared, zones before %s are those of table %s\n",
+			time.Unix(original.SharedSince, 0).UTC().Format("2006-01-02 15:04:05 UT"), original.TabName)
+	}
+
+	_, zones, _, err := tzdb.GetZoneTableMeta(int(original.ID))
+	if err != nil {
+		fmt.Printf("Zone table   : none (version %d)\n", original.TabVer)
+	} else {
+		fmt.Printf("Zone table   : %s%d (version %d, %d zones)\n", original.TabName, original.TabVer, original.TabVer, zones)
+	}
+
+	if windowsID, err := tzdb.ToWindowsID(timezone); err == nil {
+		fmt.Printf("Windows ID   : %s\n", windowsID)
+	}
+
+	overrides, err := tzdb.GetOverrides()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read overrides: %s\n", err)
+		return 1
+	}
+	for _, o := range overrides {
+		if o.Timezone == original.Name {
+			fmt.Printf("Override     : %s (%s, tzdata %s)\n", o.Name, o.Status, o.TZDVer)
+		}
+	}
+
+	return 0
+}
+
+// runLookup prints the zone in effect for a timezone at an instant.
+// The returned value is the exit status.
+func runLookup(args []string) int {
+	flags := flag.NewFlagSet("lookup", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to read")
+	locale := flags.String("locale", "", "also show display name in locale (e.g. en or en_GB)")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator lookup [-db file] [-locale l] {timezone} {time}\n")
+		fmt.Fprintf(os.Stderr, "time may be \"now\", seconds since 1970 or RFC 3339 (e.g. 2020-10-25T01:00:00Z)\n")
+		return 2
+	}
+	timezone := flags.Arg(0)
+
+	sec, err := parseInstant(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid time %q: %s\n", flags.Arg(1), err)
+		return 2
+	}
+
+	if err := openDB(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	zone, err := tzdb.Lookup(timezone, sec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lookup failed: %s\n", err)
+		return 1
+	}
+
+	local := time.Unix(sec, 0).UTC().Add(time.Duration(zone.Offset) * time.Second)
+	fmt.Printf("%s  %s = %s %s isdst=%v gmtoff=%d\n", timezone,
+		time.Unix(sec, 0).UTC().Format("2006-01-02 15:04:05 UT"),
+		local.Format("2006-01-02 15:04:05"), zone.Name, zone.IsDST, zone.Offset)
+
+	if *locale != "" {
+		name, err := tzdb.DisplayName(timezone, *locale, sec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "no display name: %s\n", err)
+			return 1
+		}
+		fmt.Printf("%s\n", name)
+	}
+
+	return 0
+}
+
+// parseInstant converts a time argument to seconds since January 1, 1970 UTC.
+// Accepted forms are "now", an integer amount of seconds and RFC 3339.
+func parseInstant(value string) (int64, error) {
+	if value == "now" {
+		return time.Now().Unix(), nil
+	}
+
+	if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return sec, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Unix(), nil
+}
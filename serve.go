@@ -0,0 +1,517 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// server answers timezone queries over HTTP, from the data of a database.
+// Originals and replicas are loaded at start-up, while zones are loaded
+// on first use and cached. When the database changes, all data are
+// loaded again and replace the previous ones at once.
+type server struct {
+	mutex sync.RWMutex
+	data  *serverData
+}
+
+// serverData holds the data of a specific generation of the database.
+type serverData struct {
+	version    string
+	generation int64
+	etag       string
+	originals  map[string]tzdb.Original // keyed by name of original
+	links      map[string]string        // name of replica --> name of original
+
+	mutex sync.RWMutex
+	zones map[string][]tzdb.Zone // keyed by name of original
+}
+
+// zoneInfo is the JSON representation of a zone.
+type zoneInfo struct {
+	Abbrev string `json:"abbrev"`
+	Offset int64  `json:"offset"`
+	IsDST  bool   `json:"is_dst"`
+	Start  int64  `json:"start"`
+	End    int64  `json:"end"` // -1 if in effect until the end of time
+}
+
+// runServe serves lookups over HTTP, until interrupted.
+// The returned value is the exit status.
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to serve")
+	addr := flags.String("addr", "127.0.0.1:8080", "address to listen on")
+	reload := flags.Duration("reload", 30*time.Second, "interval of checks for changes of database (0 to disable)")
+	flags.Parse(args)
+
+	srv := &server{}
+	var err error
+	if *reload > 0 {
+		err = tzdb.OpenWatched(*filename, *reload, func(oldVersion, newVersion string) {
+			if oldVersion != newVersion {
+				log.Printf("tzdata changed from %s to %s, reloading", oldVersion, newVersion)
+			} else {
+				log.Printf("data of tzdata %s changed, reloading", newVersion)
+			}
+			srv.reload()
+		})
+	} else {
+		err = openDB(*filename)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	if srv.data, err = loadServerData(); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot load %q: %s\n", *filename, err)
+		return 1
+	}
+
+	log.Printf("serving %q (tzdata %s) on http://%s/", *filename, srv.data.version, *addr)
+	if err := http.ListenAndServe(*addr, srv.handler()); err != nil {
+		log.Printf("server failed: %s", err)
+		return 1
+	}
+	return 0
+}
+
+// reload replaces the data of the server with fresh ones.
+// If loading fails, the server keeps the data it has.
+func (s *server) reload() {
+	data, err := loadServerData()
+	if err != nil {
+		log.Printf("reload failed: %s", err)
+		return
+	}
+
+	s.mutex.Lock()
+	s.data = data
+	s.mutex.Unlock()
+}
+
+// current returns the data that requests should be served from.
+func (s *server) current() *serverData {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.data
+}
+
+// loadServerData loads originals and replicas of the open database.
+func loadServerData() (*serverData, error) {
+	version, err := tzdb.GetTZDataVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	generation, err := tzdb.GetGeneration()
+	if err != nil {
+		return nil, err
+	}
+
+	originals, err := tzdb.GetOriginals()
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, err := tzdb.GetReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	data := &serverData{
+		version:    version,
+		generation: generation,
+		etag:       fmt.Sprintf("\"tzdata-%s-%d\"", version, generation),
+		originals:  make(map[string]tzdb.Original, len(originals)),
+		links:      make(map[string]string, len(replicas)),
+		zones:      make(map[string][]tzdb.Zone, len(originals))}
+
+	names := make(map[int64]string, len(originals))
+	for _, original := range originals {
+		data.originals[original.Name] = original
+		names[original.ID] = original.Name
+	}
+	for _, replica := range replicas {
+		if name, ok := names[replica.ProtoID]; ok {
+			data.links[replica.Name] = name
+		}
+	}
+
+	return data, nil
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/zones", s.handleZones)
+	mux.HandleFunc("/zones/", s.handleZones)
+	mux.HandleFunc("/lookup", s.handleLookup)
+	mux.HandleFunc("/transitions", s.handleTransitions)
+	mux.HandleFunc("/resolve", s.handleResolve)
+	mux.HandleFunc("/countries", s.handleCountries)
+	mux.HandleFunc("/countries/", s.handleCountries)
+	mux.HandleFunc("/nearest", s.handleNearest)
+	mux.HandleFunc("/windows", s.handleWindows)
+	mux.HandleFunc("/match", s.handleMatch)
+	mux.HandleFunc("/abbreviation", s.handleAbbreviation)
+	return mux
+}
+
+// getZones retrieves the zones of an original, from cache if possible.
+func (s *serverData) getZones(original string) ([]tzdb.Zone, error) {
+	s.mutex.RLock()
+	zones, ok := s.zones[original]
+	s.mutex.RUnlock()
+	if ok {
+		return zones, nil
+	}
+
+	zones, err := tzdb.GetEffectiveZones(original)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.zones[original] = zones
+	s.mutex.Unlock()
+
+	return zones, nil
+}
+
+// handleZones lists all timezones (/zones) or resolves a timezone
+// to its original (/zones/{timezone}).
+func (s *server) handleZones(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/zones"), "/")
+	if name == "" {
+		list := make([]map[string]string, 0, len(data.links))
+		for replica, original := range data.links {
+			list = append(list, map[string]string{"name": replica, "original": original})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i]["name"] < list[j]["name"] })
+		s.reply(w, r, data, map[string]interface{}{"tzdata_version": data.version, "zones": list}, true)
+		return
+	}
+
+	originalName, ok := data.links[name]
+	if !ok {
+		s.fail(w, http.StatusNotFound, fmt.Sprintf("unknown timezone %q", name))
+		return
+	}
+	original := data.originals[originalName]
+	s.reply(w, r, data, map[string]interface{}{
+		"name":           name,
+		"original":       original.Name,
+		"default_abbrev": original.DZone,
+		"default_offset": original.DOffset,
+		"tzdata_version": original.TZDVer}, true)
+}
+
+// handleLookup answers which zone is in effect at an instant.
+// Parameters: tz (timezone), at (instant, defaults to now) and
+// locale (optional, to get the display name of the timezone).
+func (s *server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	name, zones, ok := s.timezoneParam(w, r, data)
+	if !ok {
+		return
+	}
+
+	at, now := time.Now().Unix(), true
+	if value := r.URL.Query().Get("at"); value != "" {
+		now = value == "now"
+		var err error
+		if at, err = parseInstant(value); err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Sprintf("invalid instant %q", value))
+			return
+		}
+	}
+
+	zone, found := tzdb.LookupZone(zones, at)
+	if !found {
+		s.fail(w, http.StatusNotFound, fmt.Sprintf("no zone of %q in effect at %d", name, at))
+		return
+	}
+	body := map[string]interface{}{"timezone": name, "at": at, "zone": toZoneInfo(zone)}
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		displayName, err := tzdb.DisplayName(name, locale, at)
+		if err != nil {
+			s.fail(w, http.StatusNotFound, err.Error())
+			return
+		}
+		body["display_name"] = displayName
+	}
+	s.reply(w, r, data, body, !now)
+}
+
+// handleTransitions lists the zones in effect within a range of instants.
+// Parameters: tz (timezone), from and to (instants, default to all time).
+func (s *server) handleTransitions(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	name, zones, ok := s.timezoneParam(w, r, data)
+	if !ok {
+		return
+	}
+
+	from, to := int64(-1<<63), int64(1<<63-1)
+	for param, value := range map[string]*int64{"from": &from, "to": &to} {
+		if text := r.URL.Query().Get(param); text != "" {
+			sec, err := parseInstant(text)
+			if err != nil {
+				s.fail(w, http.StatusBadRequest, fmt.Sprintf("invalid instant %q", text))
+				return
+			}
+			*value = sec
+		}
+	}
+
+	between := tzdb.ZonesBetween(zones, from, to)
+	list := make([]zoneInfo, 0, len(between))
+	for _, zone := range between {
+		list = append(list, toZoneInfo(zone))
+	}
+	s.reply(w, r, data, map[string]interface{}{"timezone": name, "from": from, "to": to, "zones": list}, true)
+}
+
+// handleResolve converts a local time to UTC.
+// Parameters: tz (timezone), local (wall clock time, as 2006-01-02T15:04:05).
+// Local times skipped or repeated by a transition get zero or two results.
+func (s *server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	name, zones, ok := s.timezoneParam(w, r, data)
+	if !ok {
+		return
+	}
+
+	value := r.URL.Query().Get("local")
+	wall, err := time.Parse("2006-01-02T15:04:05", value)
+	if err != nil {
+		s.fail(w, http.StatusBadRequest, fmt.Sprintf("invalid local time %q", value))
+		return
+	}
+
+	instants, inEffect := tzdb.ResolveLocal(zones, wall.Unix())
+	results := make([]map[string]interface{}, 0, len(instants))
+	for i := range instants {
+		results = append(results, map[string]interface{}{
+			"utc":  time.Unix(instants[i], 0).UTC().Format(time.RFC3339),
+			"at":   instants[i],
+			"zone": toZoneInfo(inEffect[i])})
+	}
+	s.reply(w, r, data, map[string]interface{}{"timezone": name, "local": value, "results": results}, true)
+}
+
+// countryZoneInfo is the JSON representation of a timezone used in a country.
+type countryZoneInfo struct {
+	Timezone  string  `json:"timezone"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Comment   string  `json:"comment,omitempty"`
+}
+
+func toCountryZoneInfo(zone tzdb.CountryZone) countryZoneInfo {
+	return countryZoneInfo{Timezone: zone.Timezone, Country: zone.Country,
+		Latitude: zone.Latitude, Longitude: zone.Longitude, Comment: zone.Comment}
+}
+
+// handleCountries lists all countries (/countries) or the timezones
+// used in a country (/countries/{code}), most populous first.
+func (s *server) handleCountries(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	code := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/countries"), "/")
+	if code == "" {
+		countries, err := tzdb.GetCountries()
+		if err != nil {
+			s.fail(w, http.StatusInternalServerError, fmt.Sprintf("cannot load countries: %s", err))
+			return
+		}
+		list := make([]map[string]string, 0, len(countries))
+		for _, country := range countries {
+			list = append(list, map[string]string{"code": country.Code, "name": country.Name})
+		}
+		s.reply(w, r, data, map[string]interface{}{"tzdata_version": data.version, "countries": list}, true)
+		return
+	}
+
+	zones, err := tzdb.GetZonesByCountry(code)
+	if err != nil {
+		s.fail(w, http.StatusInternalServerError, fmt.Sprintf("cannot load timezones of %q: %s", code, err))
+		return
+	}
+	if len(zones) == 0 {
+		s.fail(w, http.StatusNotFound, fmt.Sprintf("no timezones for country %q", code))
+		return
+	}
+	list := make([]countryZoneInfo, 0, len(zones))
+	for _, zone := range zones {
+		list = append(list, toCountryZoneInfo(zone))
+	}
+	s.reply(w, r, data, map[string]interface{}{"country": strings.ToUpper(code), "zones": list}, true)
+}
+
+// handleNearest finds the timezone whose principal location is the nearest
+// to a point. Parameters: lat and lon (degrees, positive north and east).
+func (s *server) handleNearest(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	var coords [2]float64
+	for i, param := range []string{"lat", "lon"} {
+		value, err := strconv.ParseFloat(r.URL.Query().Get(param), 64)
+		if err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Sprintf("invalid %s %q", param, r.URL.Query().Get(param)))
+			return
+		}
+		coords[i] = value
+	}
+
+	zone, distance, err := tzdb.NearestZone(coords[0], coords[1])
+	if err != nil {
+		s.fail(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.reply(w, r, data, map[string]interface{}{"lat": coords[0], "lon": coords[1],
+		"zone": toCountryZoneInfo(zone), "distance_km": distance}, true)
+}
+
+// handleWindows maps Windows timezone IDs to timezones and back.
+// Parameters: id (Windows ID) and territory (optional country code),
+// or tz (timezone).
+func (s *server) handleWindows(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	query := r.URL.Query()
+	if name := query.Get("tz"); name != "" {
+		id, err := tzdb.ToWindowsID(name)
+		if err != nil {
+			s.fail(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.reply(w, r, data, map[string]interface{}{"timezone": name, "windows_id": id}, true)
+		return
+	}
+
+	id, territory := query.Get("id"), query.Get("territory")
+	name, err := tzdb.FromWindowsID(id, territory)
+	if err != nil {
+		s.fail(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.reply(w, r, data, map[string]interface{}{"windows_id": id, "territory": territory, "timezone": name}, true)
+}
+
+// handleMatch finds the timezones matching timestamps with offsets.
+// Parameters: obs (one or more RFC 3339 timestamps, each optionally
+// followed by a slash and an abbreviation) and country (optional hint).
+func (s *server) handleMatch(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	values := r.URL.Query()["obs"]
+	if len(values) == 0 {
+		s.fail(w, http.StatusBadRequest, "no observations given")
+		return
+	}
+
+	observations := make([]tzdb.Observation, 0, len(values))
+	for _, value := range values {
+		o, err := parseObservation(value)
+		if err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Sprintf("invalid observation %q", value))
+			return
+		}
+		observations = append(observations, o)
+	}
+
+	matches, err := tzdb.MatchOffsets(observations, r.URL.Query().Get("country"))
+	if err != nil {
+		s.fail(w, http.StatusInternalServerError, fmt.Sprintf("cannot match offsets: %s", err))
+		return
+	}
+	list := make([]map[string]interface{}, 0, len(matches))
+	for _, match := range matches {
+		list = append(list, map[string]interface{}{"timezone": match.Timezone, "countries": match.Countries})
+	}
+	s.reply(w, r, data, map[string]interface{}{"matches": list}, true)
+}
+
+// handleAbbreviation answers which timezones used an abbreviation.
+// Parameters: abbrev (abbreviation) and at (instant, defaults to now).
+func (s *server) handleAbbreviation(w http.ResponseWriter, r *http.Request) {
+	data := s.current()
+	query := r.URL.Query()
+	at, now := time.Now().Unix(), true
+	if value := query.Get("at"); value != "" {
+		now = value == "now"
+		var err error
+		if at, err = parseInstant(value); err != nil {
+			s.fail(w, http.StatusBadRequest, fmt.Sprintf("invalid instant %q", value))
+			return
+		}
+	}
+
+	uses, err := tzdb.GetByAbbreviation(query.Get("abbrev"), at)
+	if err != nil {
+		s.fail(w, http.StatusInternalServerError, fmt.Sprintf("cannot read index of abbreviations: %s", err))
+		return
+	}
+	list := make([]map[string]interface{}, 0, len(uses))
+	for _, use := range uses {
+		list = append(list, map[string]interface{}{"timezone": use.Timezone, "offset": use.Offset, "is_dst": use.IsDST})
+	}
+	s.reply(w, r, data, map[string]interface{}{"abbrev": query.Get("abbrev"), "at": at, "timezones": list}, !now)
+}
+
+// timezoneParam retrieves the zones of the timezone specified by the
+// "tz" parameter. If that fails, an error is sent to the client.
+func (s *server) timezoneParam(w http.ResponseWriter, r *http.Request, data *serverData) (string, []tzdb.Zone, bool) {
+	name := r.URL.Query().Get("tz")
+	original, ok := data.links[name]
+	if !ok {
+		s.fail(w, http.StatusNotFound, fmt.Sprintf("unknown timezone %q", name))
+		return "", nil, false
+	}
+
+	zones, err := data.getZones(original)
+	if err != nil {
+		s.fail(w, http.StatusInternalServerError, fmt.Sprintf("cannot load zones of %q: %s", name, err))
+		return "", nil, false
+	}
+
+	return name, zones, true
+}
+
+// reply sends a successful response, unless the client already has it.
+// Responses only change along with the data of the database, so the same
+// ETag is used for all of them, unless they depend on the current time.
+func (s *server) reply(w http.ResponseWriter, r *http.Request, data *serverData, body interface{}, cacheable bool) {
+	if cacheable {
+		w.Header().Set("ETag", data.etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == data.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *server) fail(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+func toZoneInfo(zone tzdb.Zone) zoneInfo {
+	return zoneInfo{Abbrev: zone.Name, Offset: zone.Offset, IsDST: zone.IsDST, Start: zone.Start, End: zone.End}
+}
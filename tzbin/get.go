@@ -0,0 +1,193 @@
+package tzbin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// GetTZDataVersion retrieves the version of TZ-data of the open file.
+func GetTZDataVersion() (string, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return "", noFile
+	}
+
+	return file.version, nil
+}
+
+// GetOriginal retrieves the original timezone for specified timezone.
+// The specified timezone is treated as a replica (link), as in GetZones.
+func GetOriginal(timezone string) (*Original, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return nil, noFile
+	}
+
+	index, err := file.findOriginal(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	original, _, err := file.original(index)
+	if err != nil {
+		return nil, err
+	}
+	return &original, nil
+}
+
+// GetOriginals retrieves all original timezones, sorted by name.
+func GetOriginals() ([]Original, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return nil, noFile
+	}
+
+	originals := make([]Original, 0, file.count)
+	for i := 0; i < file.count; i++ {
+		original, _, err := file.original(i)
+		if err != nil {
+			return nil, err
+		}
+		originals = append(originals, original)
+	}
+	return originals, nil
+}
+
+// GetReplicas retrieves all timezones, along with their originals, sorted by name.
+func GetReplicas() ([]Replica, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return nil, noFile
+	}
+
+	count := len(file.names) / nameSize
+	replicas := make([]Replica, 0, count)
+	for i := 0; i < count; i++ {
+		name, err := file.str(binary.LittleEndian.Uint32(file.names[i*nameSize:]))
+		if err != nil {
+			return nil, err
+		}
+		original, _, err := file.original(int(binary.LittleEndian.Uint32(file.names[i*nameSize+4:])))
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, Replica{Name: name, Original: original.Name})
+	}
+	return replicas, nil
+}
+
+// GetZones retrieves the zones of specified timezone.
+// The specified timezone is treated as a replica (link)
+// which is first translated to the corresponding original.
+func GetZones(timezone string) ([]Zone, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return nil, noFile
+	}
+
+	zones, original, err := getZones(timezone)
+	if err == nil && zones == nil {
+		return nil, fmt.Errorf("tzbin: no zones stored for %q", original.Name)
+	}
+	return zones, err
+}
+
+// getZones retrieves the zones of a timezone, along with its original.
+// Zones are nil, without an error, if the original has no zones.
+func getZones(timezone string) ([]Zone, *Original, error) {
+	index, err := file.findOriginal(timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	original, offset, err := file.original(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	if offset == noZones {
+		return nil, &original, nil
+	}
+
+	zones, err := file.decodeZones(offset)
+	return zones, &original, err
+}
+
+// GetEffectiveZones retrieves the zones of specified timezone, like GetZones.
+// Timezones without any zones get their default zone, which extends from the
+// beginning to the end of time.
+func GetEffectiveZones(timezone string) ([]Zone, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return nil, noFile
+	}
+
+	return getEffectiveZones(timezone)
+}
+
+func getEffectiveZones(timezone string) ([]Zone, error) {
+	zones, original, err := getZones(timezone)
+	if err == nil && zones == nil {
+		return []Zone{{Name: original.DZone, Offset: original.DOffset, Start: math.MinInt64, End: -1}}, nil
+	}
+	return zones, err
+}
+
+// LookupZone returns the zone in effect at the specified instant,
+// expressed in seconds since January 1, 1970 UTC. Zones should be
+// sorted by start time, as returned by GetZones. The boolean result
+// is false if the instant is not covered by any of the zones.
+func LookupZone(zones []Zone, sec int64) (Zone, bool) {
+	// find first zone that starts after sec
+	i := sort.Search(len(zones), func(i int) bool {
+		return zones[i].Start > sec
+	})
+	if i == 0 {
+		return Zone{}, false
+	}
+
+	zone := zones[i-1]
+	if zone.End != -1 && sec > zone.End {
+		return Zone{}, false
+	}
+
+	return zone, true
+}
+
+// Lookup returns the zone in effect for specified timezone at the
+// specified instant, expressed in seconds since January 1, 1970 UTC.
+// Timezones without any zones get their default zone, which extends
+// from the beginning to the end of time.
+func Lookup(timezone string, sec int64) (Zone, error) {
+	fileLock.RLock()
+	defer fileLock.RUnlock()
+
+	if file == nil {
+		return Zone{}, noFile
+	}
+
+	zones, err := getEffectiveZones(timezone)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	zone, ok := LookupZone(zones, sec)
+	if !ok {
+		return Zone{}, fmt.Errorf("tzbin: no zone of %q in effect at %d", timezone, sec)
+	}
+
+	return zone, nil
+}
@@ -0,0 +1,267 @@
+package tzbin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+)
+
+// binFile holds the sections of an open file.
+type binFile struct {
+	originals []byte
+	names     []byte
+	zones     []byte
+	strings   []byte
+	count     int // originals
+	version   string
+}
+
+var (
+	file     *binFile
+	fileLock sync.RWMutex
+)
+
+// Open reads the specified file as a whole and keeps it in memory.
+func Open(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return OpenBytes(data)
+}
+
+// OpenBytes uses the specified data, which may be embedded or mmap-ed.
+// Data are not copied, so they should not change while open.
+func OpenBytes(data []byte) error {
+	f, err := parseHeader(data)
+	if err != nil {
+		return err
+	}
+
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	file = f
+	return nil
+}
+
+// Close forgets the data of the open file.
+func Close() error {
+	fileLock.Lock()
+	defer fileLock.Unlock()
+
+	if file == nil {
+		return noFile
+	}
+
+	file = nil
+	return nil
+}
+
+func parseHeader(data []byte) (*binFile, error) {
+	if len(data) < headerSize || string(data[:4]) != magic {
+		return nil, fmt.Errorf("tzbin: not a tzbin file")
+	}
+	if version := binary.LittleEndian.Uint16(data[4:]); version != Version {
+		return nil, fmt.Errorf("tzbin: unsupported version %d", version)
+	}
+
+	u32 := func(offset int) int {
+		return int(binary.LittleEndian.Uint32(data[offset:]))
+	}
+	section := func(offset, size int) ([]byte, error) {
+		if offset < headerSize || size < 0 || offset > len(data) || size > len(data)-offset {
+			return nil, errFormat
+		}
+		return data[offset : offset+size], nil
+	}
+
+	f := &binFile{count: u32(12)}
+	var err error
+	if f.strings, err = section(u32(20), u32(24)); err != nil {
+		return nil, err
+	}
+	if f.originals, err = section(u32(28), f.count*originalSize); err != nil {
+		return nil, err
+	}
+	if f.names, err = section(u32(32), u32(16)*nameSize); err != nil {
+		return nil, err
+	}
+	if f.zones, err = section(u32(36), u32(40)); err != nil {
+		return nil, err
+	}
+	if f.version, err = f.str(uint32(u32(8))); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// str retrieves a string from the table of strings.
+func (f *binFile) str(offset uint32) (string, error) {
+	if uint64(offset) >= uint64(len(f.strings)) {
+		return "", errFormat
+	}
+	size, n := binary.Uvarint(f.strings[offset:])
+	start := uint64(offset) + uint64(n)
+	if n <= 0 || size > uint64(len(f.strings))-start {
+		return "", errFormat
+	}
+	return string(f.strings[start : start+size]), nil
+}
+
+// compare compares a string of the table with a name, without copying it.
+func (f *binFile) compare(offset uint32, name string) (int, error) {
+	if uint64(offset) >= uint64(len(f.strings)) {
+		return 0, errFormat
+	}
+	size, n := binary.Uvarint(f.strings[offset:])
+	start := uint64(offset) + uint64(n)
+	if n <= 0 || size > uint64(len(f.strings))-start {
+		return 0, errFormat
+	}
+	return bytes.Compare(f.strings[start:start+size], []byte(name)), nil
+}
+
+// original decodes the record of the original with the specified index.
+func (f *binFile) original(index int) (Original, uint32, error) {
+	if index < 0 || index >= f.count {
+		return Original{}, 0, errFormat
+	}
+	record := f.originals[index*originalSize:]
+	field := func(i int) uint32 {
+		return binary.LittleEndian.Uint32(record[4*i:])
+	}
+
+	var o Original
+	var err error
+	for i, s := range []*string{&o.Name, &o.Footer, &o.DZone} {
+		if *s, err = f.str(field(i)); err != nil {
+			return Original{}, 0, err
+		}
+	}
+	o.DOffset = int64(int32(field(3)))
+	if o.TZDVer, err = f.str(field(4)); err != nil {
+		return Original{}, 0, err
+	}
+	if since := int64(uint64(field(6)) | uint64(field(7))<<32); since != notClipped {
+		o.Clipped = true
+		o.Since = since
+	}
+
+	return o, field(5), nil
+}
+
+// findOriginal resolves a timezone to the index of its original.
+func (f *binFile) findOriginal(timezone string) (int, error) {
+	count := len(f.names) / nameSize
+	var err error
+	i := sort.Search(count, func(i int) bool {
+		c, e := f.compare(binary.LittleEndian.Uint32(f.names[i*nameSize:]), timezone)
+		if e != nil {
+			err = e
+		}
+		return c >= 0
+	})
+	if err != nil {
+		return 0, err
+	}
+	if i == count {
+		return 0, fmt.Errorf("tzbin: unknown timezone %q", timezone)
+	}
+	if c, err := f.compare(binary.LittleEndian.Uint32(f.names[i*nameSize:]), timezone); err != nil || c != 0 {
+		return 0, fmt.Errorf("tzbin: unknown timezone %q", timezone)
+	}
+
+	return int(binary.LittleEndian.Uint32(f.names[i*nameSize+4:])), nil
+}
+
+// decodeZones decodes the zones stored at the specified offset.
+func (f *binFile) decodeZones(offset uint32) ([]Zone, error) {
+	if uint64(offset) >= uint64(len(f.zones)) {
+		return nil, errFormat
+	}
+	data := f.zones[offset:]
+	pos := 0
+	// on failure, pos is moved past the end of data
+	uvarint := func() uint64 {
+		if pos > len(data) {
+			return 0
+		}
+		v, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			pos = len(data) + 1
+			return 0
+		}
+		pos += n
+		return v
+	}
+	varint := func() int64 {
+		if pos > len(data) {
+			return 0
+		}
+		v, n := binary.Varint(data[pos:])
+		if n <= 0 {
+			pos = len(data) + 1
+			return 0
+		}
+		pos += n
+		return v
+	}
+
+	typeCount := uvarint()
+	if typeCount == 0 || typeCount > 256 {
+		return nil, errFormat
+	}
+	types := make([]Zone, typeCount)
+	for i := range types {
+		types[i].Offset = varint()
+		if pos >= len(data) {
+			return nil, errFormat
+		}
+		types[i].IsDST = data[pos]&flagDST != 0
+		types[i].IsStd = data[pos]&flagStd != 0
+		types[i].IsUT = data[pos]&flagUT != 0
+		pos++
+		abbrev, err := f.str(uint32(uvarint()))
+		if err != nil || pos > len(data) {
+			return nil, errFormat
+		}
+		types[i].Name = abbrev
+	}
+
+	count := uvarint()
+	if pos > len(data) || count > uint64(len(data)-pos) {
+		return nil, errFormat
+	}
+	zones := make([]Zone, count)
+	start := int64(0)
+	for i := range zones {
+		start += varint()
+		zones[i].Start = start
+	}
+	if pos > len(data) || uint64(len(data)-pos) < count {
+		return nil, errFormat
+	}
+	for i := range zones {
+		index := int(data[pos+i])
+		if index >= len(types) {
+			return nil, errFormat
+		}
+		zones[i].Name = types[index].Name
+		zones[i].Offset = types[index].Offset
+		zones[i].IsDST = types[index].IsDST
+		zones[i].IsStd = types[index].IsStd
+		zones[i].IsUT = types[index].IsUT
+		zones[i].End = -1
+		if i > 0 {
+			zones[i-1].End = zones[i].Start - 1
+		}
+	}
+
+	return zones, nil
+}
@@ -0,0 +1,95 @@
+// tzbin stores the timezones of a database in a compact binary format
+// and reads them back, offering the same queries as tzdb. Unlike tzdb,
+// it is written in pure Go, so it can be used where sqlite cannot,
+// e.g. in mobile and WASM clients.
+//
+// A file consists of a header, a table of originals, an index of names,
+// the zones of each original and a table of strings. All integers are
+// little-endian. Tables have fixed-size records and strings are referred
+// to by offset, so that a file can be queried in place (e.g. mmap-ed)
+// without being decoded first. Only zones are decoded, on demand.
+//
+//	header     48 bytes, see below
+//	originals  32 bytes each: name, footer, default zone (string offsets),
+//	           default offset (int32), tzdata version (string offset),
+//	           offset of zones in zone data (noZones if none), instant
+//	           zones were clipped at (int64, notClipped if they were not)
+//	names       8 bytes each: name (string offset), index of original,
+//	           for every timezone (originals and replicas), sorted by name
+//	zone data  for each original with zones: count of types (uvarint),
+//	           each type as offset (varint), flags (byte: flagDST, flagStd,
+//	           flagUT), abbreviation (string offset, uvarint), then count
+//	           of transitions (uvarint), start of each transition as
+//	           difference from the previous one (varint) and type of each
+//	           transition (byte)
+//	strings    each one as length (uvarint) and bytes; offset 0 is ""
+//
+// The end of each zone is not stored, as it is the start of the next zone
+// minus one, or -1 for the last one.
+package tzbin
+
+import (
+	"errors"
+	"math"
+)
+
+// Version of the format written by Write.
+const Version = 3
+
+const (
+	magic        = "TZBN"
+	headerSize   = 48
+	originalSize = 32
+	nameSize     = 8
+	noZones      = 0xFFFFFFFF
+	notClipped   = math.MinInt64
+)
+
+// flags of types of zones
+const (
+	flagDST = 1 << iota
+	flagStd // standard time indicator
+	flagUT  // UT indicator
+)
+
+var (
+	noFile    = errors.New("tzbin: no file open")
+	errFormat = errors.New("tzbin: invalid or corrupt file")
+)
+
+// Original defines a unique timezone, as in tzdb.
+type Original struct {
+	Name    string
+	DZone   string // Get this Zone when no zones are defined!
+	DOffset int64  // Get this Offset when no zones are defined!
+	TZDVer  string // Version of TZ-data the timezone was updated with
+	Footer  string // TZ string for instants after the last zone, if any
+	Clipped bool   // Are zones before Since missing?
+	Since   int64  // Zones before this instant were dropped, if Clipped
+}
+
+// Replica defines a link to some timezone.
+// Every original is also a replica of itself.
+type Replica struct {
+	Name     string
+	Original string
+}
+
+// Zone defines a zone within a timezone, as in tzdb.
+type Zone struct {
+	Name   string
+	Start  int64
+	End    int64 // -1 if in effect until the end of time
+	Offset int64
+	IsDST  bool
+	IsStd  bool // standard time indicator of the local time type (RFC 8536)
+	IsUT   bool // UT indicator of the local time type (RFC 8536)
+}
+
+// Snapshot holds all data to be written in a file.
+type Snapshot struct {
+	TZDataVersion string
+	Originals     []Original
+	Replicas      map[string]string // name of replica --> name of original
+	Zones         map[string][]Zone // name of original --> zones, if any
+}
@@ -0,0 +1,101 @@
+package tzbin
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		TZDataVersion: "2020d",
+		Originals: []Original{
+			{Name: "Europe/Athens", DZone: "EET", DOffset: 7200, TZDVer: "2020d", Footer: "EET-2EEST,M3.5.0/3,M10.5.0/4",
+				Clipped: true, Since: -2344642492},
+			{Name: "Etc/UTC", DZone: "UTC", TZDVer: "2020d"}},
+		Replicas: map[string]string{
+			"Europe/Athens": "Europe/Athens",
+			"Etc/UTC":       "Etc/UTC",
+			"UTC":           "Etc/UTC",
+			"Zulu":          "Etc/UTC",
+			"Atlantis":      "Atlantis/Central"}, // original is missing
+		Zones: map[string][]Zone{
+			"Europe/Athens": {
+				{Name: "AMT", Offset: 5692, Start: -2344642492, End: -1686101633},
+				{Name: "EET", Offset: 7200, Start: -1686101632, End: -1182996001},
+				{Name: "EEST", Offset: 10800, IsDST: true, Start: -1182996000, End: -1178161201},
+				{Name: "EET", Offset: 7200, IsStd: true, IsUT: true, Start: -1178161200, End: -1}}}}
+}
+
+func TestRoundTrip(t *testing.T) {
+	snap := testSnapshot()
+	buf := &bytes.Buffer{}
+	if err := Write(buf, snap); err != nil {
+		t.Fatalf("cannot write: %s", err)
+	}
+	if err := OpenBytes(buf.Bytes()); err != nil {
+		t.Fatalf("cannot open: %s", err)
+	}
+	defer Close()
+
+	if version, err := GetTZDataVersion(); err != nil || version != "2020d" {
+		t.Errorf("GetTZDataVersion() = %q, %v", version, err)
+	}
+
+	zones, err := GetZones("Europe/Athens")
+	if err != nil || !reflect.DeepEqual(zones, snap.Zones["Europe/Athens"]) {
+		t.Errorf("GetZones() = %v, %v, want %v", zones, err, snap.Zones["Europe/Athens"])
+	}
+
+	original, err := GetOriginal("Zulu")
+	if err != nil || *original != snap.Originals[1] {
+		t.Errorf("GetOriginal(%q) = %v, %v", "Zulu", original, err)
+	}
+	if original, err := GetOriginal("Europe/Athens"); err != nil || *original != snap.Originals[0] {
+		t.Errorf("GetOriginal(%q) = %v, %v", "Europe/Athens", original, err)
+	}
+
+	if _, err := GetZones("UTC"); err == nil {
+		t.Errorf("GetZones(%q) should fail, as no zones are stored", "UTC")
+	}
+	want := Zone{Name: "UTC", Start: math.MinInt64, End: -1}
+	if zone, err := Lookup("UTC", 0); err != nil || zone != want {
+		t.Errorf("Lookup(%q) = %v, %v, want %v", "UTC", zone, err, want)
+	}
+
+	if zone, err := Lookup("Europe/Athens", -1182996000); err != nil || zone.Name != "EEST" {
+		t.Errorf("Lookup(%q) = %v, %v, want EEST", "Europe/Athens", zone, err)
+	}
+
+	for _, name := range []string{"Atlantis", "Europe", "Zz", ""} {
+		if _, err := GetOriginal(name); err == nil {
+			t.Errorf("GetOriginal(%q) should fail", name)
+		}
+	}
+
+	replicas, err := GetReplicas()
+	if err != nil || len(replicas) != 4 {
+		t.Errorf("GetReplicas() = %v, %v, want 4 replicas", replicas, err)
+	}
+}
+
+func TestCorruptFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(buf, testSnapshot()); err != nil {
+		t.Fatalf("cannot write: %s", err)
+	}
+	data := buf.Bytes()
+
+	// truncated files should result in errors, not in panics
+	for size := 0; size < len(data); size++ {
+		if err := OpenBytes(data[:size]); err != nil {
+			continue
+		}
+		for _, name := range []string{"Europe/Athens", "UTC"} {
+			GetEffectiveZones(name)
+		}
+		GetReplicas()
+	}
+	Close()
+}
@@ -0,0 +1,206 @@
+package tzbin
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// stringTable collects strings, storing each one only once.
+type stringTable struct {
+	data    []byte
+	offsets map[string]uint32
+}
+
+func newStringTable() *stringTable {
+	t := &stringTable{offsets: make(map[string]uint32)}
+	t.ref("")
+	return t
+}
+
+// ref returns the offset of a string, adding it if needed.
+func (t *stringTable) ref(s string) uint32 {
+	if offset, ok := t.offsets[s]; ok {
+		return offset
+	}
+	offset := uint32(len(t.data))
+	t.data = appendUvarint(t.data, uint64(len(s)))
+	t.data = append(t.data, s...)
+	t.offsets[s] = offset
+	return offset
+}
+
+// Write stores a snapshot in the binary format.
+// Zones of each original should be sorted and contiguous,
+// as those of a database that passes tzdb.Check.
+// Replicas that point to unknown originals are left out.
+func Write(w io.Writer, snap *Snapshot) error {
+	strs := newStringTable()
+
+	originals := make([]Original, len(snap.Originals))
+	copy(originals, snap.Originals)
+	sort.Slice(originals, func(i, j int) bool { return originals[i].Name < originals[j].Name })
+
+	index := make(map[string]uint32, len(originals))
+	records := make([]byte, 0, len(originals)*originalSize)
+	zoneData := make([]byte, 0, 64*1024)
+	for i, original := range originals {
+		if _, dup := index[original.Name]; dup {
+			return fmt.Errorf("tzbin: original %q appears twice", original.Name)
+		}
+		index[original.Name] = uint32(i)
+
+		zonesOffset := uint32(noZones)
+		if zones := snap.Zones[original.Name]; len(zones) != 0 {
+			zonesOffset = uint32(len(zoneData))
+			var err error
+			if zoneData, err = appendZones(zoneData, zones, strs); err != nil {
+				return fmt.Errorf("tzbin: cannot store zones of %q: %s", original.Name, err)
+			}
+		}
+
+		records = appendU32(records, strs.ref(original.Name))
+		records = appendU32(records, strs.ref(original.Footer))
+		records = appendU32(records, strs.ref(original.DZone))
+		records = appendU32(records, uint32(int32(original.DOffset)))
+		records = appendU32(records, strs.ref(original.TZDVer))
+		records = appendU32(records, zonesOffset)
+		since := int64(notClipped)
+		if original.Clipped {
+			since = original.Since
+		}
+		records = appendU32(records, uint32(since))
+		records = appendU32(records, uint32(since>>32))
+	}
+
+	names := make([]string, 0, len(snap.Replicas)+len(originals))
+	targets := make(map[string]uint32, cap(names))
+	for _, original := range originals {
+		names = append(names, original.Name)
+		targets[original.Name] = index[original.Name]
+	}
+	for replica, original := range snap.Replicas {
+		i, ok := index[original]
+		if !ok {
+			continue
+		}
+		if _, known := targets[replica]; !known {
+			names = append(names, replica)
+		}
+		targets[replica] = i
+	}
+	sort.Strings(names)
+
+	nameRecords := make([]byte, 0, len(names)*nameSize)
+	for _, name := range names {
+		nameRecords = appendU32(nameRecords, strs.ref(name))
+		nameRecords = appendU32(nameRecords, targets[name])
+	}
+
+	versionRef := strs.ref(snap.TZDataVersion)
+
+	originalsOff := uint32(headerSize)
+	namesOff := originalsOff + uint32(len(records))
+	dataOff := namesOff + uint32(len(nameRecords))
+	stringsOff := dataOff + uint32(len(zoneData))
+	if uint64(stringsOff)+uint64(len(strs.data)) >= noZones {
+		return fmt.Errorf("tzbin: too much data for the format")
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = appendU16(header, Version)
+	header = appendU16(header, 0)
+	header = appendU32(header, versionRef)
+	header = appendU32(header, uint32(len(originals)))
+	header = appendU32(header, uint32(len(names)))
+	header = appendU32(header, stringsOff)
+	header = appendU32(header, uint32(len(strs.data)))
+	header = appendU32(header, originalsOff)
+	header = appendU32(header, namesOff)
+	header = appendU32(header, dataOff)
+	header = appendU32(header, uint32(len(zoneData)))
+	header = appendU32(header, 0)
+
+	for _, section := range [][]byte{header, records, nameRecords, zoneData, strs.data} {
+		if _, err := w.Write(section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendZones encodes the zones of an original.
+func appendZones(data []byte, zones []Zone, strs *stringTable) ([]byte, error) {
+	type zoneType struct {
+		offset int64
+		flags  byte
+		abbrev string
+	}
+
+	types := make([]zoneType, 0, 8)
+	typeIndex := make(map[zoneType]int, 8)
+	transTypes := make([]byte, 0, len(zones))
+	for i, zone := range zones {
+		last := i == len(zones)-1
+		if (last && zone.End != -1) || (!last && zone.End != zones[i+1].Start-1) {
+			return nil, fmt.Errorf("zone #%d is not followed by the next one", i)
+		}
+
+		t := zoneType{offset: zone.Offset, abbrev: zone.Name}
+		if zone.IsDST {
+			t.flags |= flagDST
+		}
+		if zone.IsStd {
+			t.flags |= flagStd
+		}
+		if zone.IsUT {
+			t.flags |= flagUT
+		}
+		index, known := typeIndex[t]
+		if !known {
+			if len(types) == 256 {
+				return nil, fmt.Errorf("more than 256 types of zones")
+			}
+			index = len(types)
+			typeIndex[t] = index
+			types = append(types, t)
+		}
+		transTypes = append(transTypes, byte(index))
+	}
+
+	data = appendUvarint(data, uint64(len(types)))
+	for _, t := range types {
+		data = appendVarint(data, t.offset)
+		data = append(data, t.flags)
+		data = appendUvarint(data, uint64(strs.ref(t.abbrev)))
+	}
+
+	data = appendUvarint(data, uint64(len(zones)))
+	previous := int64(0)
+	for _, zone := range zones {
+		// wraps around for the earliest starts, just like decoding does
+		data = appendVarint(data, zone.Start-previous)
+		previous = zone.Start
+	}
+	return append(data, transTypes...), nil
+}
+
+func appendU16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendU32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
@@ -48,9 +48,10 @@ func GetList() (version string, timezones map[string]string, err error) {
 	scanner := bufio.NewScanner(file)
 
 	// get version string from first line
-	scanner.Scan()
-	substr := strings.Fields(scanner.Text())
-	version = substr[2]
+	if version, err = scanVersion(scanner); err != nil {
+		return "", nil, err
+	}
+	var substr []string
 
 	// scan the rest of the lines
 	timezones = make(map[string]string, 500)
@@ -72,3 +73,29 @@ func GetList() (version string, timezones map[string]string, err error) {
 
 	return version, timezones, nil
 }
+
+// GetVersion reads only the version of tzdata.zi, which is cheap
+// enough to be used for checking whether the source has changed.
+func GetVersion() (string, error) {
+	file, err := os.Open(source_path + "tzdata.zi")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return scanVersion(bufio.NewScanner(file))
+}
+
+// scanVersion extracts the version from the first line
+// of tzdata.zi, which reads like "# version 2020d".
+func scanVersion(scanner *bufio.Scanner) (string, error) {
+	scanner.Scan()
+	substr := strings.Fields(scanner.Text())
+	if len(substr) < 3 || substr[1] != "version" {
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", errors.New("tzdata: no version in tzdata.zi")
+	}
+	return substr[2], nil
+}
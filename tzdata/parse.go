@@ -92,6 +92,8 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 			version = 2
 		case '3':
 			version = 3
+		case '4':
+			version = 4
 		default:
 			return nil, badData
 		}
@@ -124,7 +126,7 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 		cnt[i] = int(cntval)
 	}
 
-	// If we have version 2 or 3, then the data is first written out
+	// If we have version 2 or later, then the data is first written out
 	// in a 32-bit format, then written out again in a 64-bit format.
 	// Skip the 32-bit format and read the 64-bit one, as it can
 	// describe a broader range of dates.
@@ -179,7 +181,7 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 	abbrev := d.read(cnt[NChar])
 
 	// Leap-second time pairs
-	d.read(cnt[NLeap] * (size + 4))
+	leapdata := dataIO{d.read(cnt[NLeap] * (size + 4)), false}
 
 	// Whether tx times associated with local time types
 	// are specified as standard time or wall time.
@@ -189,6 +191,11 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 	// are specified as UTC or local time.
 	isutc := d.read(cnt[NUTCLocal])
 
+	// Indicators are either missing or given for every type.
+	if (cnt[NStdWall] != 0 && cnt[NStdWall] != cnt[NZone]) || (cnt[NUTCLocal] != 0 && cnt[NUTCLocal] != cnt[NZone]) {
+		return nil, badData
+	}
+
 	if d.error { // ran out of data
 		return nil, badData
 	}
@@ -229,6 +236,15 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 			return nil, badData
 		}
 		eras[i].Name = byteString(abbrev[b:])
+
+		// indicators are given per local time type, if at all
+		eras[i].IsUT = i < len(isutc) && isutc[i] != 0
+		eras[i].IsStd = eras[i].IsUT || (i < len(isstd) && isstd[i] != 0)
+	}
+
+	leaps, leapExpires, ok := parseLeapSeconds(&leapdata, cnt[NLeap], is64, version)
+	if !ok {
+		return nil, badData
 	}
 
 	// Now the transition time info.
@@ -257,13 +273,6 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 		}
 		tx[i].Index = txzones[i]
 
-		if i < len(isstd) {
-			tx[i].Isstd = (isstd[i] != 0)
-		}
-
-		if i < len(isutc) {
-			tx[i].Isutc = (isutc[i] != 0)
-		}
 	}
 
 	// Check if TZ string has been defined
@@ -322,7 +331,61 @@ func parseRawTZdata(name string, data []byte) (*TZdata, error) {
 		ti--
 	}
 
-	l := &TZdata{Eras: eras, Trans: tx, Name: name, Extend: extend}
+	l := &TZdata{Eras: eras, Trans: tx, Name: name, Extend: extend, Leaps: leaps, LeapExpires: leapExpires}
 
 	return l, nil
 }
+
+// parseLeapSeconds reads the records of the table of leap seconds. Before
+// version 4, corrections start at +1 or -1 and each one differs from the
+// previous by one second. Since version 4 (RFC 9636), the table may be
+// truncated at the start, so the first correction can be any value, and
+// a last record with the same correction as the previous one is not a
+// leap second but the expiration of the table.
+func parseLeapSeconds(d *dataIO, n int, is64 bool, version int) (leaps []LeapSecond, expires int64, ok bool) {
+	leaps = make([]LeapSecond, 0, n)
+	for i := 0; i < n; i++ {
+		var when int64
+		if is64 {
+			n8, ok := d.big8()
+			if !ok {
+				return nil, 0, false
+			}
+			when = int64(n8)
+		} else {
+			n4, ok := d.big4()
+			if !ok {
+				return nil, 0, false
+			}
+			when = int64(int32(n4))
+		}
+		n4, ok := d.big4()
+		if !ok {
+			return nil, 0, false
+		}
+		correction := int(int32(n4))
+
+		prev := 0
+		if len(leaps) > 0 {
+			last := leaps[len(leaps)-1]
+			if when <= last.When {
+				return nil, 0, false
+			}
+			prev = last.Correction
+		}
+
+		switch diff := correction - prev; {
+		case i == 0 && version >= 4:
+			// table truncated at the start
+		case diff == 1 || diff == -1:
+		case i == n-1 && i > 0 && diff == 0 && version >= 4:
+			expires = when
+			continue
+		default:
+			return nil, 0, false
+		}
+		leaps = append(leaps, LeapSecond{When: when, Correction: correction})
+	}
+
+	return leaps, expires, true
+}
@@ -0,0 +1,195 @@
+package tzdata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// tzifSpec describes the content of a hand-built TZif file.
+type tzifSpec struct {
+	version byte // 0, '2', '3', '4' etc.
+	trans   []int64
+	index   []byte
+	eras    []Era
+	leaps   []LeapSecond
+	footer  string
+}
+
+// build writes the TZif file described. Files of version 2 and later
+// repeat the data in the 32-bit block of version 1, as zic does, but
+// only for instants that fit in 32 bits.
+func (spec tzifSpec) build() []byte {
+	buf := &bytes.Buffer{}
+	put := func(v interface{}) {
+		binary.Write(buf, binary.BigEndian, v)
+	}
+
+	chars := make([]byte, 0)
+	charIndex := make([]byte, len(spec.eras))
+	for i, era := range spec.eras {
+		charIndex[i] = byte(len(chars))
+		chars = append(append(chars, era.Name...), 0)
+	}
+
+	block := func(version byte, is64 bool) {
+		trans, index := spec.trans, spec.index
+		if !is64 {
+			trans, index = nil, nil
+			for i, when := range spec.trans {
+				if when == int64(int32(when)) {
+					trans = append(trans, when)
+					index = append(index, spec.index[i])
+				}
+			}
+		}
+
+		buf.WriteString("TZif")
+		buf.WriteByte(version)
+		buf.Write(make([]byte, 15))
+		for _, count := range []int{len(spec.eras), len(spec.eras), len(spec.leaps), len(trans), len(spec.eras), len(chars)} {
+			put(uint32(count))
+		}
+		for _, when := range trans {
+			if is64 {
+				put(when)
+			} else {
+				put(int32(when))
+			}
+		}
+		buf.Write(index)
+		for i, era := range spec.eras {
+			put(int32(era.Offset))
+			put(era.IsDST)
+			put(charIndex[i])
+		}
+		buf.Write(chars)
+		for _, leap := range spec.leaps {
+			if is64 {
+				put(leap.When)
+			} else {
+				put(int32(leap.When))
+			}
+			put(int32(leap.Correction))
+		}
+		for _, era := range spec.eras {
+			put(era.IsStd)
+		}
+		for _, era := range spec.eras {
+			put(era.IsUT)
+		}
+	}
+
+	block(spec.version, false)
+	if spec.version != 0 {
+		block(spec.version, true)
+		buf.WriteString("\n" + spec.footer + "\n")
+	}
+	return buf.Bytes()
+}
+
+var tzifEras = []Era{
+	{Name: "LMT", Offset: 5692},
+	{Name: "EEST", Offset: 10800, IsDST: true, IsStd: true, IsUT: true},
+	{Name: "EET", Offset: 7200, IsStd: true, IsUT: true},
+}
+
+func TestParseVersions(t *testing.T) {
+	const footer = "EET-2EEST,M3.5.0/3,M10.5.0/4"
+	tests := []struct {
+		name  string
+		spec  tzifSpec
+		trans int // transitions expected, before those of the footer
+	}{
+		{"version 1", tzifSpec{version: 0, trans: []int64{-1686101632, 354675600, 370400400}, index: []byte{2, 1, 2}, eras: tzifEras}, 3},
+		{"version 2", tzifSpec{version: '2', trans: []int64{-2344642492, 354675600, 370400400}, index: []byte{2, 1, 2}, eras: tzifEras, footer: footer}, 3},
+		// version 3 allows TZ strings with rule times beyond 24 hours
+		{"version 3", tzifSpec{version: '3', trans: []int64{354675600, 370400400}, index: []byte{1, 2}, eras: tzifEras, footer: "EET-2EEST,M3.5.0/-1,M10.5.0/25"}, 2},
+		{"version 4", tzifSpec{version: '4', trans: []int64{354675600, 370400400}, index: []byte{1, 2}, eras: tzifEras, footer: footer}, 2},
+	}
+
+	for _, test := range tests {
+		data, err := parseRawTZdata(test.name, test.spec.build())
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if data.Extend != test.spec.footer {
+			t.Errorf("%s: got footer %q, want %q", test.name, data.Extend, test.spec.footer)
+		}
+		if len(data.Trans) < test.trans {
+			t.Errorf("%s: got %d transitions, want at least %d", test.name, len(data.Trans), test.trans)
+			continue
+		}
+		for i := 0; i < test.trans; i++ {
+			if data.Trans[i].When != test.spec.trans[i] || data.era(i) != tzifEras[test.spec.index[i]] {
+				t.Errorf("%s: transition %d is %+v, want %d to %+v", test.name, i, data.Trans[i], test.spec.trans[i], tzifEras[test.spec.index[i]])
+			}
+		}
+	}
+}
+
+func TestParseLeapSeconds(t *testing.T) {
+	base := tzifSpec{trans: []int64{354675600}, index: []byte{1}, eras: tzifEras}
+	leaps := []LeapSecond{{When: 78796800, Correction: 1}, {When: 94694401, Correction: 2}}
+	// version 4 tables may start with any correction and end with expiration
+	truncated := []LeapSecond{{When: 1483228826, Correction: 27}, {When: 1782864027, Correction: 27}}
+
+	tests := []struct {
+		name    string
+		version byte
+		leaps   []LeapSecond
+		ok      bool
+		count   int   // leap seconds expected
+		expires int64 // expiration expected
+	}{
+		{"version 1", 0, leaps, true, 2, 0},
+		{"version 2", '2', leaps, true, 2, 0},
+		{"version 3", '3', leaps, true, 2, 0},
+		{"version 4", '4', leaps, true, 2, 0},
+		{"version 4, truncated with expiration", '4', truncated, true, 1, 1782864027},
+		{"version 3, truncated", '3', truncated[:1], false, 0, 0},
+		{"version 3, with expiration", '3', []LeapSecond{leaps[0], {When: 94694401, Correction: 1}}, false, 0, 0},
+		{"version 4, skipped correction", '4', []LeapSecond{leaps[0], {When: 94694401, Correction: 3}, {When: 99999999, Correction: 4}}, false, 0, 0},
+		{"version 4, out of order", '4', []LeapSecond{leaps[1], {When: 78796800, Correction: 3}}, false, 0, 0},
+	}
+
+	for _, test := range tests {
+		spec := base
+		spec.version, spec.leaps = test.version, test.leaps
+		data, err := parseRawTZdata(test.name, spec.build())
+		if !test.ok {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if len(data.Leaps) != test.count || data.LeapExpires != test.expires {
+			t.Errorf("%s: got leap seconds %+v expiring at %d, want %d expiring at %d",
+				test.name, data.Leaps, data.LeapExpires, test.count, test.expires)
+		}
+	}
+}
+
+func TestParseBadVersion(t *testing.T) {
+	for _, version := range []byte{'1', '5', 'A'} {
+		spec := tzifSpec{version: version, trans: []int64{354675600}, index: []byte{1}, eras: tzifEras}
+		if _, err := parseRawTZdata("bad", spec.build()); err == nil {
+			t.Errorf("version %q: expected error, got none", version)
+		}
+	}
+}
+
+func TestParseRightZone(t *testing.T) {
+	data, err := readTZfile("right/UTC")
+	if err != nil {
+		t.Skipf("no timezone files with leap seconds: %s", err)
+	}
+	if len(data.Leaps) == 0 || data.Leaps[0].Correction != 1 {
+		t.Errorf("got leap seconds %+v, want table starting with +1", data.Leaps)
+	}
+}
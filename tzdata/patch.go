@@ -0,0 +1,154 @@
+package tzdata
+
+import (
+	"errors"
+	"sort"
+)
+
+// Clone returns a copy of the data, which can be patched
+// without affecting the original.
+func (d *TZdata) Clone() *TZdata {
+	c := *d
+	c.Eras = append([]Era(nil), d.Eras...)
+	c.Trans = append([]EraTrans(nil), d.Trans...)
+	return &c
+}
+
+// Equal reports whether two sets of data describe the same transitions,
+// regardless of the way eras are indexed and of their indicators.
+func (d *TZdata) Equal(o *TZdata) bool {
+	if d.Extend != o.Extend || len(d.Trans) != len(o.Trans) || !sameEra(d.FirstEra(), o.FirstEra()) {
+		return false
+	}
+	for i := range d.Trans {
+		if d.Trans[i].When != o.Trans[i].When || !sameEra(d.era(i), o.era(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// era returns the era that goes into effect with the specified transition.
+func (d *TZdata) era(i int) Era {
+	tx := d.Trans[i]
+	if tx.Index == 255 {
+		return Era{Name: tx.AltName, Offset: tx.AltOffset}
+	}
+	return d.Eras[tx.Index]
+}
+
+// sameEra reports whether two eras describe the same local time,
+// regardless of their indicators.
+func sameEra(a, b Era) bool {
+	return a.Name == b.Name && a.Offset == b.Offset && a.IsDST == b.IsDST
+}
+
+// eraIndex returns the index of an era, adding it if needed. An existing
+// era is reused regardless of its indicators, which patches cannot know.
+// Index 255 is reserved for transitions to undefined eras.
+func (d *TZdata) eraIndex(era Era) (uint8, error) {
+	for i := range d.Eras {
+		if sameEra(d.Eras[i], era) {
+			return uint8(i), nil
+		}
+	}
+	if len(d.Eras) >= 255 {
+		return 0, errors.New("tzdata: too many eras")
+	}
+	d.Eras = append(d.Eras, era)
+	return uint8(len(d.Eras) - 1), nil
+}
+
+// SetTransition makes the specified era go into effect at the specified
+// instant, replacing the transition at that instant, if there is one.
+func (d *TZdata) SetTransition(at int64, era Era) error {
+	index, err := d.eraIndex(era)
+	if err != nil {
+		return err
+	}
+
+	i := sort.Search(len(d.Trans), func(i int) bool { return d.Trans[i].When >= at })
+	tx := EraTrans{When: at, Index: index}
+	if i < len(d.Trans) && d.Trans[i].When == at {
+		d.Trans[i] = tx
+		return nil
+	}
+
+	d.Trans = append(d.Trans, EraTrans{})
+	copy(d.Trans[i+1:], d.Trans[i:])
+	d.Trans[i] = tx
+	return nil
+}
+
+// RemoveTransition removes the transition at the specified instant.
+// The result is false if there is no transition at that instant.
+func (d *TZdata) RemoveTransition(at int64) bool {
+	i := sort.Search(len(d.Trans), func(i int) bool { return d.Trans[i].When >= at })
+	if i == len(d.Trans) || d.Trans[i].When != at {
+		return false
+	}
+
+	d.Trans = append(d.Trans[:i], d.Trans[i+1:]...)
+	return true
+}
+
+// ReplaceRule makes the specified rule (a TZ string, as in Extend) apply
+// from the specified instant on. Later transitions are replaced by those
+// of the rule, up to the instant that the data used to cover or for four
+// more years, whichever is later. The rule also becomes the new Extend.
+func (d *TZdata) ReplaceRule(from int64, rule string) error {
+	r, err := ParsePOSIXTZ(rule)
+	if err != nil {
+		return err
+	}
+
+	horizon := from + 4*31536000
+	if len(d.Trans) > 0 && d.Trans[len(d.Trans)-1].When > horizon {
+		horizon = d.Trans[len(d.Trans)-1].When
+	}
+
+	// era in effect before the rule applies
+	prevName, prevOffset, _, _ := d.Lookup(from - 1)
+
+	i := sort.Search(len(d.Trans), func(i int) bool { return d.Trans[i].When >= from })
+	d.Trans = d.Trans[:i]
+	d.Extend = rule
+
+	// transition times of TZ strings are given in wall clock time,
+	// so new eras have neither the standard nor the UT indicator set
+	for sec := from; sec <= horizon; {
+		name, offset, isDST, _, end := r.Lookup(sec)
+		if name != prevName || offset != prevOffset {
+			if err := d.SetTransition(sec, Era{Name: name, Offset: offset, IsDST: isDST}); err != nil {
+				return err
+			}
+		}
+		prevName, prevOffset = name, offset
+		sec = end
+	}
+
+	return nil
+}
+
+// Clip drops the transitions before the specified instant. If an era was
+// in effect at that instant, a transition to it is inserted at the instant,
+// so that the data still describe the era in effect from then on. The last
+// transition is always kept, since the Extend string applies after it. The
+// result is false if there was nothing to drop.
+func (d *TZdata) Clip(since int64) bool {
+	i := sort.Search(len(d.Trans), func(i int) bool { return d.Trans[i].When >= since })
+	if i == len(d.Trans) {
+		i = len(d.Trans) - 1
+	}
+	if i <= 0 {
+		return false
+	}
+
+	if d.Trans[i].When > since {
+		// the era of the previous transition is in effect at since
+		i--
+		d.Trans[i].When = since
+	}
+	d.Trans = append(d.Trans[:0], d.Trans[i:]...)
+	return true
+}
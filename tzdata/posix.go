@@ -0,0 +1,228 @@
+package tzdata
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// RuleKind is the form of the date of a transition in a TZ string.
+type RuleKind int
+
+const (
+	RuleJulian       RuleKind = iota // Jn: day 1 to 365, February 29 is never counted
+	RuleDOY                          // n: day 0 to 365, February 29 is counted in leap years
+	RuleMonthWeekDay                 // Mm.w.d: day d (0 is Sunday) of week w (5 is last) of month m
+)
+
+// RuleDate is the date and local time of a yearly transition in a TZ string.
+type RuleDate struct {
+	Kind  RuleKind `json:"kind"`
+	Day   int      `json:"day"`             // day of year, or day of week for RuleMonthWeekDay
+	Week  int      `json:"week,omitempty"`  // for RuleMonthWeekDay only
+	Month int      `json:"month,omitempty"` // for RuleMonthWeekDay only
+	Time  int      `json:"time"`            // seconds since local midnight, -167 to 167 hours
+}
+
+// Rule is a parsed TZ string, as found in the footer of timezone files:
+// the POSIX format, along with the extensions of RFC 8536, that is rule
+// times from -167 to 167 hours and daylight saving time all year.
+type Rule struct {
+	StdName   string   `json:"std_name"`
+	StdOffset int      `json:"std_offset"`         // seconds east of UTC
+	DSTName   string   `json:"dst_name,omitempty"` // empty if there is no daylight saving time
+	DSTOffset int      `json:"dst_offset,omitempty"`
+	Start     RuleDate `json:"start"` // start of daylight saving time, in standard time
+	End       RuleDate `json:"end"`   // end of daylight saving time, in daylight saving time
+}
+
+// ParsePOSIXTZ parses a TZ string (e.g. "EET-2EEST,M3.5.0/3,M10.5.0/4").
+// Daylight saving time without rules follows the US rules, as in tzcode.
+func ParsePOSIXTZ(s string) (*Rule, error) {
+	invalid := errors.New("tzdata: invalid TZ string " + s)
+	var r Rule
+	var ok bool
+
+	r.StdName, s, ok = tzsetName(s)
+	if ok {
+		r.StdOffset, s, ok = tzsetOffset(s)
+	}
+	if !ok {
+		return nil, invalid
+	}
+
+	// The numbers in the tzset string are added to local time to get UTC,
+	// but our offsets are added to UTC to get local time,
+	// so we negate the number we see here.
+	r.StdOffset = -r.StdOffset
+
+	if len(s) == 0 || s[0] == ',' {
+		// No daylight savings time.
+		return &r, nil
+	}
+
+	r.DSTName, s, ok = tzsetName(s)
+	if ok {
+		if len(s) == 0 || s[0] == ',' {
+			r.DSTOffset = r.StdOffset + secondsPerHour
+		} else {
+			r.DSTOffset, s, ok = tzsetOffset(s)
+			r.DSTOffset = -r.DSTOffset // as with StdOffset, above
+		}
+	}
+	if !ok {
+		return nil, invalid
+	}
+
+	if len(s) == 0 {
+		// Default DST rules per tzcode.
+		s = ",M3.2.0,M11.1.0"
+	}
+	// The TZ definition does not mention ';' here but tzcode accepts it.
+	if s[0] != ',' && s[0] != ';' {
+		return nil, invalid
+	}
+
+	r.Start, s, ok = tzsetRule(s[1:])
+	if !ok || len(s) == 0 || s[0] != ',' {
+		return nil, invalid
+	}
+	r.End, s, ok = tzsetRule(s[1:])
+	if !ok || len(s) > 0 {
+		return nil, invalid
+	}
+
+	return &r, nil
+}
+
+// HasDST reports whether the rule has daylight saving time at all.
+func (r *Rule) HasDST() bool {
+	return r.DSTName != ""
+}
+
+// AllYearDST reports whether daylight saving time is in effect all year,
+// that is, it starts on January 1 at 00:00 and ends on December 31 at 24:00
+// plus the difference between daylight saving and standard time, or later
+// (e.g. "EST5EDT,0/0,J365/25").
+func (r *Rule) AllYearDST() bool {
+	if !r.HasDST() {
+		return false
+	}
+	// Julian and DOY rules differ between leap and common years
+	for _, year := range []int{2023, 2024} {
+		length := 365 * secondsPerDay
+		if isLeap(year) {
+			length += secondsPerDay
+		}
+		if tzruleTime(year, r.Start, r.StdOffset) > -r.StdOffset ||
+			tzruleTime(year, r.End, r.DSTOffset) < length-r.StdOffset {
+			return false
+		}
+	}
+	return true
+}
+
+// Lookup returns the zone in effect at an instant, expressed in seconds since
+// January 1, 1970 UTC, and the instants the zone starts and ends, that is the
+// start of the next one. Zones in effect for ever start and end at the limits
+// of int64.
+func (r *Rule) Lookup(sec int64) (name string, offset int, isDST bool, start, end int64) {
+	if !r.HasDST() {
+		return r.StdName, r.StdOffset, false, bigbang, gnabgib
+	}
+	if r.AllYearDST() {
+		return r.DSTName, r.DSTOffset, true, bigbang, gnabgib
+	}
+
+	// Rule times may move transitions up to a week into the
+	// next or previous year, so collect those of nearby years.
+	type transition struct {
+		when  int64
+		isDST bool
+	}
+	year, _, _, _ := absDate(uint64(sec+unixToInternal+internalToAbsolute), false)
+	transitions := make([]transition, 0, 10)
+	for y := year - 2; y <= year+2; y++ {
+		abs := yearStart(y)
+		transitions = append(transitions,
+			transition{abs + int64(tzruleTime(y, r.Start, r.StdOffset)), true},
+			transition{abs + int64(tzruleTime(y, r.End, r.DSTOffset)), false})
+	}
+	sort.Slice(transitions, func(i, j int) bool { return transitions[i].when < transitions[j].when })
+
+	i := sort.Search(len(transitions), func(i int) bool { return transitions[i].when > sec })
+	// i is neither the first nor past the last, as transitions cover five years
+	start, end, isDST = transitions[i-1].when, transitions[i].when, transitions[i-1].isDST
+	if isDST {
+		return r.DSTName, r.DSTOffset, true, start, end
+	}
+	return r.StdName, r.StdOffset, false, start, end
+}
+
+// String returns the rule as a TZ string. Parsing it gives back the same rule.
+func (r *Rule) String() string {
+	s := formatTZName(r.StdName) + formatTZTime(-r.StdOffset)
+	if !r.HasDST() {
+		return s
+	}
+
+	s += formatTZName(r.DSTName)
+	if r.DSTOffset != r.StdOffset+secondsPerHour {
+		s += formatTZTime(-r.DSTOffset)
+	}
+	return s + "," + r.Start.String() + "," + r.End.String()
+}
+
+// String returns the date as in a TZ string. The time is left
+// out if it is the default, 02:00.
+func (d RuleDate) String() string {
+	var s string
+	switch d.Kind {
+	case RuleJulian:
+		s = fmt.Sprintf("J%d", d.Day)
+	case RuleDOY:
+		s = fmt.Sprintf("%d", d.Day)
+	default:
+		s = fmt.Sprintf("M%d.%d.%d", d.Month, d.Week, d.Day)
+	}
+
+	if d.Time != 2*secondsPerHour {
+		s += "/" + formatTZTime(d.Time)
+	}
+	return s
+}
+
+// formatTZName formats a name of a zone for a TZ string,
+// quoting names that are not made of letters only.
+func formatTZName(name string) string {
+	for _, r := range name {
+		if !isAlpha(r) {
+			return "<" + name + ">"
+		}
+	}
+	return name
+}
+
+// formatTZTime formats an amount of seconds as hours, with minutes
+// and seconds only if needed, for a TZ string.
+func formatTZTime(sec int) string {
+	sign := ""
+	if sec < 0 {
+		sign, sec = "-", -sec
+	}
+
+	hours, mins, secs := sec/secondsPerHour, sec%secondsPerHour/secondsPerMinute, sec%secondsPerMinute
+	switch {
+	case secs != 0:
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, hours, mins, secs)
+	case mins != 0:
+		return fmt.Sprintf("%s%d:%02d", sign, hours, mins)
+	default:
+		return fmt.Sprintf("%s%d", sign, hours)
+	}
+}
+
+// yearStart returns the start of a year, in seconds since January 1, 1970 UTC.
+func yearStart(year int) int64 {
+	return int64(daysSinceEpoch(year)*secondsPerDay) + absoluteToInternal + internalToUnix
+}
@@ -0,0 +1,104 @@
+package tzdata
+
+import (
+	"testing"
+)
+
+func TestParsePOSIXTZ(t *testing.T) {
+	tests := []struct {
+		in      string
+		out     string // as printed back, if different
+		dst     bool
+		allYear bool
+	}{
+		{"UTC0", "", false, false},
+		{"<+03>-3", "", false, false},
+		{"<-0330>3:30", "", false, false},
+		{"EET-2EEST,M3.5.0/3,M10.5.0/4", "", true, false},
+		{"EST5EDT", "EST5EDT,M3.2.0,M11.1.0", true, false},
+		{"<-03>3<-02>,M3.5.0/-2,M10.5.0/-1", "", true, false},
+		{"IST-2IDT,M3.4.4/26,M10.5.0", "", true, false},
+		{"AAA3BBB,J60/167,J300/-167", "", true, false},
+		{"WART4WARST,0/0,365/25", "", true, true},
+		{"EST5EDT,0/0,J365/25", "", true, true},
+		{"XXX-1YYY-3,M3.5.0/1:30:15,M10.5.0", "", true, false},
+	}
+
+	for _, test := range tests {
+		r, err := ParsePOSIXTZ(test.in)
+		if err != nil {
+			t.Errorf("ParsePOSIXTZ(%q): %s", test.in, err)
+			continue
+		}
+		if r.HasDST() != test.dst || r.AllYearDST() != test.allYear {
+			t.Errorf("ParsePOSIXTZ(%q) = %+v, want dst %v, all year %v", test.in, r, test.dst, test.allYear)
+		}
+
+		want := test.out
+		if want == "" {
+			want = test.in
+		}
+		if got := r.String(); got != want {
+			t.Errorf("ParsePOSIXTZ(%q).String() = %q, want %q", test.in, got, want)
+		}
+		if again, err := ParsePOSIXTZ(r.String()); err != nil || *again != *r {
+			t.Errorf("ParsePOSIXTZ(%q) = %+v, %v, want %+v", r.String(), again, err, r)
+		}
+	}
+}
+
+func TestParsePOSIXTZInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"AB3",
+		"<ab>3",
+		"<+03",
+		"A1B3",
+		"EST25",
+		"EST5EDT,M3.2.0",
+		"EST5EDT,M3.2.0/168,M11.1.0",
+		"EST5EDT,M13.2.0,M11.1.0",
+		"EST5EDT,J0,J365",
+		"EST5EDT,366,J365",
+		"EST5EDT,M3.2.0,M11.1.0x",
+	} {
+		if r, err := ParsePOSIXTZ(s); err == nil {
+			t.Errorf("ParsePOSIXTZ(%q) = %+v, want error", s, r)
+		}
+	}
+}
+
+func TestRuleLookup(t *testing.T) {
+	tests := []struct {
+		rule       string
+		sec        int64
+		name       string
+		offset     int
+		isDST      bool
+		start, end int64
+	}{
+		// 2023-03-26 01:00 UT to 2023-10-29 01:00 UT
+		{"EET-2EEST,M3.5.0/3,M10.5.0/4", 1690000000, "EEST", 10800, true, 1679792400, 1698541200},
+		{"EET-2EEST,M3.5.0/3,M10.5.0/4", 1679792400, "EEST", 10800, true, 1679792400, 1698541200},
+		{"EET-2EEST,M3.5.0/3,M10.5.0/4", 1679792399, "EET", 7200, false, 1667091600, 1679792400},
+		// southern hemisphere, across the new year:
+		// 2022-10-01 16:00 UT to 2023-04-01 16:00 UT
+		{"AEST-10AEDT,M10.1.0,M4.1.0/3", 1672531200, "AEDT", 39600, true, 1664640000, 1680364800},
+		{"AEST-10AEDT,M10.1.0,M4.1.0/3", 1680364800, "AEST", 36000, false, 1680364800, 1696089600},
+		{"<+03>-3", 1672531200, "+03", 10800, false, bigbang, gnabgib},
+		{"EST5EDT,0/0,J365/25", 1672531200, "EDT", -14400, true, bigbang, gnabgib},
+	}
+
+	for _, test := range tests {
+		r, err := ParsePOSIXTZ(test.rule)
+		if err != nil {
+			t.Errorf("ParsePOSIXTZ(%q): %s", test.rule, err)
+			continue
+		}
+		name, offset, isDST, start, end := r.Lookup(test.sec)
+		if name != test.name || offset != test.offset || isDST != test.isDST || start != test.start || end != test.end {
+			t.Errorf("%q at %d: got %s %d %v %d-%d, want %s %d %v %d-%d", test.rule, test.sec,
+				name, offset, isDST, start, end, test.name, test.offset, test.isDST, test.start, test.end)
+		}
+	}
+}
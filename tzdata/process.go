@@ -121,6 +121,15 @@ func (d *TZdata) Lookup(sec int64) (name string, offset int, start, end int64) {
 	return
 }
 
+// FirstEra returns the era in effect before the first transition,
+// or at any time, if there are no transitions at all.
+func (d *TZdata) FirstEra() Era {
+	if len(d.Eras) == 0 {
+		return Era{Name: "UTC"}
+	}
+	return d.Eras[d.getFirstZone()]
+}
+
 // lookupFirstZone returns the index of the time zone to use for times
 // before the first transition time, or when there are no transition
 // times.
@@ -180,71 +189,29 @@ func (d *TZdata) firstZoneUsed() bool {
 // The return values are as for lookup, plus ok which reports whether the
 // parse succeeded.
 func tzset(s string, initEnd, sec int64) (name string, offset int, start, end int64, ok bool) {
-	var (
-		stdName, dstName     string
-		stdOffset, dstOffset int
-	)
-
-	stdName, s, ok = tzsetName(s)
-	if ok {
-		stdOffset, s, ok = tzsetOffset(s)
-	}
-	if !ok {
+	r, err := ParsePOSIXTZ(s)
+	if err != nil {
 		return "", 0, 0, 0, false
 	}
 
-	// The numbers in the tzset string are added to local time to get UTC,
-	// but our offsets are added to UTC to get local time,
-	// so we negate the number we see here.
-	stdOffset = -stdOffset
-
-	if len(s) == 0 || s[0] == ',' {
+	if !r.HasDST() {
 		// No daylight savings time.
-		return stdName, stdOffset, initEnd, gnabgib, true
-	}
-
-	dstName, s, ok = tzsetName(s)
-	if ok {
-		if len(s) == 0 || s[0] == ',' {
-			dstOffset = stdOffset + secondsPerHour
-		} else {
-			dstOffset, s, ok = tzsetOffset(s)
-			dstOffset = -dstOffset // as with stdOffset, above
-		}
-	}
-	if !ok {
-		return "", 0, 0, 0, false
+		return r.StdName, r.StdOffset, initEnd, gnabgib, true
 	}
-
-	if len(s) == 0 {
-		// Default DST rules per tzcode.
-		s = ",M3.2.0,M11.1.0"
+	if r.AllYearDST() {
+		return r.DSTName, r.DSTOffset, initEnd, gnabgib, true
 	}
-	// The TZ definition does not mention ';' here but tzcode accepts it.
-	if s[0] != ',' && s[0] != ';' {
-		return "", 0, 0, 0, false
-	}
-	s = s[1:]
 
-	var startRule, endRule rule
-	startRule, s, ok = tzsetRule(s)
-	if !ok || len(s) == 0 || s[0] != ',' {
-		return "", 0, 0, 0, false
-	}
-	s = s[1:]
-	endRule, s, ok = tzsetRule(s)
-	if !ok || len(s) > 0 {
-		return "", 0, 0, 0, false
-	}
+	stdName, dstName := r.StdName, r.DSTName
+	stdOffset, dstOffset := r.StdOffset, r.DSTOffset
+	startRule, endRule := r.Start, r.End
 
 	year, _, _, yday := absDate(uint64(sec+unixToInternal+internalToAbsolute), false)
 
 	ysec := int64(yday*secondsPerDay) + sec%secondsPerDay
 
 	// Compute start of year in seconds since Unix epoch.
-	d := daysSinceEpoch(year)
-	abs := int64(d * secondsPerDay)
-	abs += absoluteToInternal + internalToUnix
+	abs := yearStart(year)
 
 	startSec := int64(tzruleTime(year, startRule, stdOffset))
 	endSec := int64(tzruleTime(year, endRule, dstOffset))
@@ -282,25 +249,47 @@ func tzsetName(s string) (string, string, bool) {
 				}
 				return s[:i], s[i:], true
 			}
+			if !isAlpha(r) {
+				return "", "", false
+			}
 		}
 		if len(s) < 3 {
 			return "", "", false
 		}
 		return s, "", true
 	} else {
-		for i, r := range s {
+		// quoted names have at least three letters, digits, '+' or '-'
+		for i, r := range s[1:] {
 			if r == '>' {
-				return s[1:i], s[i+1:], true
+				if i < 3 {
+					return "", "", false
+				}
+				return s[1 : i+1], s[i+2:], true
+			}
+			if !isAlpha(r) && (r < '0' || r > '9') && r != '+' && r != '-' {
+				return "", "", false
 			}
 		}
 		return "", "", false
 	}
 }
 
+// isAlpha reports whether r is a letter of the portable character set.
+func isAlpha(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
 // tzsetOffset returns the timezone offset at the start of the tzset string s,
 // and the remainder of s, and reports whether the parsing is OK.
 // The timezone offset is returned as a number of seconds.
 func tzsetOffset(s string) (offset int, rest string, ok bool) {
+	return tzsetTime(s, 24)
+}
+
+// tzsetTime returns a signed amount of hours, minutes and seconds at the
+// start of the tzset string s, and the remainder of s, and reports whether
+// the parsing is OK. Hours must not exceed max.
+func tzsetTime(s string, max int) (offset int, rest string, ok bool) {
 	if len(s) == 0 {
 		return 0, "", false
 	}
@@ -313,7 +302,7 @@ func tzsetOffset(s string) (offset int, rest string, ok bool) {
 	}
 
 	var hours int
-	hours, s, ok = tzsetNum(s, 0, 24)
+	hours, s, ok = tzsetNum(s, 0, max)
 	if !ok {
 		return 0, "", false
 	}
@@ -351,80 +340,63 @@ func tzsetOffset(s string) (offset int, rest string, ok bool) {
 	return off, s, true
 }
 
-// ruleKind is the kinds of rules that can be seen in a tzset string.
-type ruleKind int
-
-const (
-	ruleJulian ruleKind = iota
-	ruleDOY
-	ruleMonthWeekDay
-)
-
-// rule is a rule read from a tzset string.
-type rule struct {
-	kind ruleKind
-	day  int
-	week int
-	mon  int
-	time int // transition time
-}
-
 // tzsetRule parses a rule from a tzset string.
 // It returns the rule, and the remainder of the string, and reports success.
-func tzsetRule(s string) (rule, string, bool) {
-	var r rule
+func tzsetRule(s string) (RuleDate, string, bool) {
+	var r RuleDate
 	if len(s) == 0 {
-		return rule{}, "", false
+		return RuleDate{}, "", false
 	}
 	ok := false
 	if s[0] == 'J' {
 		var jday int
 		jday, s, ok = tzsetNum(s[1:], 1, 365)
 		if !ok {
-			return rule{}, "", false
+			return RuleDate{}, "", false
 		}
-		r.kind = ruleJulian
-		r.day = jday
+		r.Kind = RuleJulian
+		r.Day = jday
 	} else if s[0] == 'M' {
 		var mon int
 		mon, s, ok = tzsetNum(s[1:], 1, 12)
 		if !ok || len(s) == 0 || s[0] != '.' {
-			return rule{}, "", false
+			return RuleDate{}, "", false
 		}
 		var week int
 		week, s, ok = tzsetNum(s[1:], 1, 5)
 		if !ok || len(s) == 0 || s[0] != '.' {
-			return rule{}, "", false
+			return RuleDate{}, "", false
 		}
 		var day int
 		day, s, ok = tzsetNum(s[1:], 0, 6)
 		if !ok {
-			return rule{}, "", false
+			return RuleDate{}, "", false
 		}
-		r.kind = ruleMonthWeekDay
-		r.day = day
-		r.week = week
-		r.mon = mon
+		r.Kind = RuleMonthWeekDay
+		r.Day = day
+		r.Week = week
+		r.Month = mon
 	} else {
 		var day int
 		day, s, ok = tzsetNum(s, 0, 365)
 		if !ok {
-			return rule{}, "", false
+			return RuleDate{}, "", false
 		}
-		r.kind = ruleDOY
-		r.day = day
+		r.Kind = RuleDOY
+		r.Day = day
 	}
 
 	if len(s) == 0 || s[0] != '/' {
-		r.time = 2 * secondsPerHour // 2am is the default
+		r.Time = 2 * secondsPerHour // 2am is the default
 		return r, s, true
 	}
 
-	offset, s, ok := tzsetOffset(s[1:])
-	if !ok || offset < 0 {
-		return rule{}, "", false
+	// RFC 8536 extends times to -167 through 167 hours
+	offset, s, ok := tzsetTime(s[1:], 167)
+	if !ok {
+		return RuleDate{}, "", false
 	}
-	r.time = offset
+	r.Time = offset
 
 	return r, s, true
 }
@@ -459,21 +431,21 @@ func tzsetNum(s string, min, max int) (num int, rest string, ok bool) {
 // tzruleTime takes a year, a rule, and a timezone offset,
 // and returns the number of seconds since the start of the year
 // that the rule takes effect.
-func tzruleTime(year int, r rule, off int) int {
+func tzruleTime(year int, r RuleDate, off int) int {
 	var s int
-	switch r.kind {
-	case ruleJulian:
-		s = (r.day - 1) * secondsPerDay
-		if isLeap(year) && r.day >= 60 {
+	switch r.Kind {
+	case RuleJulian:
+		s = (r.Day - 1) * secondsPerDay
+		if isLeap(year) && r.Day >= 60 {
 			s += secondsPerDay
 		}
-	case ruleDOY:
-		s = r.day * secondsPerDay
-	case ruleMonthWeekDay:
+	case RuleDOY:
+		s = r.Day * secondsPerDay
+	case RuleMonthWeekDay:
 		// Zeller's Congruence.
-		m1 := (r.mon+9)%12 + 1
+		m1 := (r.Month+9)%12 + 1
 		yy0 := year
-		if r.mon <= 2 {
+		if r.Month <= 2 {
 			yy0--
 		}
 		yy1 := yy0 / 100
@@ -482,26 +454,26 @@ func tzruleTime(year int, r rule, off int) int {
 		if dow < 0 {
 			dow += 7
 		}
-		// Now dow is the day-of-week of the first day of r.mon.
+		// Now dow is the day-of-week of the first day of r.Month.
 		// Get the day-of-month of the first "dow" day.
-		d := r.day - dow
+		d := r.Day - dow
 		if d < 0 {
 			d += 7
 		}
-		for i := 1; i < r.week; i++ {
-			if d+7 >= daysIn(Month(r.mon), year) {
+		for i := 1; i < r.Week; i++ {
+			if d+7 >= daysIn(Month(r.Month), year) {
 				break
 			}
 			d += 7
 		}
-		d += int(daysBefore[r.mon-1])
-		if isLeap(year) && r.mon > 2 {
+		d += int(daysBefore[r.Month-1])
+		if isLeap(year) && r.Month > 2 {
 			d++
 		}
 		s = d * secondsPerDay
 	}
 
-	return s + r.time - off
+	return s + r.Time - off
 }
 
 // absDate is like date but operates on an absolute time.
@@ -0,0 +1,140 @@
+package tzdata
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TabEntry is a line of zone.tab or zone1970.tab, that is, a timezone
+// with the countries it is used in, the coordinates of its principal
+// location and an optional comment.
+type TabEntry struct {
+	Countries []string // ISO 3166 alpha-2 codes, just one in zone.tab
+	Timezone  string
+	Latitude  float64 // degrees, positive north of the equator
+	Longitude float64 // degrees, positive east of Greenwich
+	Comment   string
+}
+
+// GetZoneTab reads a table of timezones of the source, that is, zone.tab
+// or zone1970.tab. Entries are returned in the order of the table, which
+// groups them by country and puts the most populous timezones first.
+func GetZoneTab(name string) ([]TabEntry, error) {
+	lines, err := readTab(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TabEntry, 0, len(lines))
+	for _, fields := range lines {
+		if len(fields) < 3 {
+			return nil, errors.New("tzdata: malformed line in " + name)
+		}
+
+		lat, lon, ok := parseCoordinates(fields[1])
+		if !ok {
+			return nil, errors.New("tzdata: bad coordinates " + fields[1] + " in " + name)
+		}
+
+		entry := TabEntry{Countries: strings.Split(fields[0], ","), Timezone: fields[2], Latitude: lat, Longitude: lon}
+		if len(fields) > 3 {
+			entry.Comment = fields[3]
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// GetCountries reads the names of countries from iso3166.tab,
+// keyed by their ISO 3166 alpha-2 code.
+func GetCountries() (map[string]string, error) {
+	lines, err := readTab("iso3166.tab")
+	if err != nil {
+		return nil, err
+	}
+
+	countries := make(map[string]string, len(lines))
+	for _, fields := range lines {
+		if len(fields) < 2 {
+			return nil, errors.New("tzdata: malformed line in iso3166.tab")
+		}
+		countries[fields[0]] = fields[1]
+	}
+
+	return countries, nil
+}
+
+// readTab reads the tab-separated fields of each line of a table
+// of the source, skipping comments.
+func readTab(name string) ([][]string, error) {
+	file, err := os.Open(source_path + name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := make([][]string, 0, 500)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		lines = append(lines, strings.Split(line, "\t"))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parseCoordinates converts coordinates in ISO 6709 sign-degrees-minutes-seconds
+// format, either ±DDMM±DDDMM or ±DDMMSS±DDDMMSS, to degrees.
+func parseCoordinates(s string) (lat, lon float64, ok bool) {
+	if len(s) < 2 {
+		return 0, 0, false
+	}
+	i := strings.IndexAny(s[1:], "+-") + 1
+	if i == 0 {
+		return 0, 0, false
+	}
+
+	lat, ok = parseDegrees(s[:i], 2)
+	if ok {
+		lon, ok = parseDegrees(s[i:], 3)
+	}
+	return lat, lon, ok
+}
+
+// parseDegrees converts a signed angle of the specified
+// amount of degree digits, followed by minutes and optionally seconds.
+func parseDegrees(s string, digits int) (float64, bool) {
+	if len(s) != 1+digits+2 && len(s) != 1+digits+4 || (s[0] != '+' && s[0] != '-') {
+		return 0, false
+	}
+
+	var parts [3]float64
+	for i, j := 0, 1; j < len(s); i++ {
+		n := 2
+		if i == 0 {
+			n = digits
+		}
+		value, err := strconv.Atoi(s[j : j+n])
+		if err != nil {
+			return 0, false
+		}
+		parts[i] = float64(value)
+		j += n
+	}
+
+	degrees := parts[0] + parts[1]/60 + parts[2]/3600
+	if s[0] == '-' {
+		degrees = -degrees
+	}
+	return degrees, true
+}
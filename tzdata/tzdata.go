@@ -3,15 +3,37 @@
 // locations. The code is stripped down to the absolute
 // minimum, in order to only run on Linux and always use
 // the timezone files installed on the system. All available
-// data are exposed and some methods for printing data
-// in a meaningful(*) way have been added.
+// data are exposed, so that they can be stored or printed
+// in a meaningful(*) way.
 //
 // (*) Yes, tzdata was built with a specific application in mind
 // and it is doubtful it will be of any use to others.
 
 package tzdata
 
-const source_path string = "/usr/share/zoneinfo/"
+import (
+	"strings"
+)
+
+// DefaultSourcePath is the location of the zoneinfo source on Linux.
+const DefaultSourcePath = "/usr/share/zoneinfo/"
+
+var source_path string = DefaultSourcePath
+
+// SetSourcePath sets the directory where timezone files
+// and tzdata.zi are read from. It is not safe to call it
+// while data are being read.
+func SetSourcePath(path string) {
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	source_path = path
+}
+
+// SourcePath returns the directory where timezone files are read from.
+func SourcePath() string {
+	return source_path
+}
 
 // TZdata collects time offsets and offset-transitions for a geographical area.
 // Typically, the TZdata struct represents the collection of time offsets
@@ -27,22 +49,37 @@ type TZdata struct {
 	// The format is the TZ environment variable without a colon;
 	// https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap08.html.
 	Extend string
+
+	// Leap-second corrections, only found in timezone files that count
+	// leap seconds (e.g. those under "right/"), and the instant the table
+	// of leap seconds expires, if given (version 4 and later), or else 0.
+	Leaps       []LeapSecond
+	LeapExpires int64
+}
+
+// A LeapSecond is a record of the table of leap seconds of a timezone file.
+type LeapSecond struct {
+	When       int64 // instant of occurrence, in seconds since 1970 GMT, counting leap seconds
+	Correction int   // total correction in effect after it, in seconds
 }
 
 // A zone represents a single time zone (CET, CEST, etc).
+// The indicators of RFC 8536 belong to the local time type: they tell
+// whether transition times of rules to this zone were given in standard
+// or UT time, rather than wall clock time. They do not affect When of
+// transitions, which is always UT, but only handling of POSIX TZ strings.
 type Era struct {
 	Name   string // abbreviated name of zone
 	Offset int    // seconds east of UTC
 	IsDST  bool   // is this zone Daylight Savings Time?
+	IsStd  bool   // are transition times to this zone standard time?
+	IsUT   bool   // are transition times to this zone UT? Implies IsStd.
 }
 
 // A zoneTrans represents a single time zone transition.
 type EraTrans struct {
-	When         int64  // transition time, in seconds since 1970 GMT
-	Index        uint8  // index of the zone that goes into effect at that time
-	AltName      string // Zone name   -- used when Index == 255
-	AltOffset    int    // Zone offset -- used when Index == 255
-	Isstd, Isutc bool   // seems to be ignored
-	// supposed to indicate whether transition time (When)
-	// expressed in UTC or local
+	When      int64  // transition time, in seconds since 1970 GMT
+	Index     uint8  // index of the zone that goes into effect at that time
+	AltName   string // Zone name   -- used when Index == 255
+	AltOffset int    // Zone offset -- used when Index == 255
 }
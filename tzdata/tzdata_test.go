@@ -2,6 +2,8 @@ package tzdata
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -46,6 +48,24 @@ func TestGetList(t *testing.T) {
 	}
 }
 
+func TestGetVersion(t *testing.T) {
+	listVersion, _, err := GetList()
+	if err != nil {
+		t.Fatalf("\nFailed: %s\n", err)
+	}
+
+	version, err := GetVersion()
+	if err != nil || version != listVersion {
+		t.Errorf("GetVersion() = %q, %v, want %q", version, err, listVersion)
+	}
+
+	SetSourcePath("/nonexistent")
+	defer SetSourcePath(DefaultSourcePath)
+	if _, err := GetVersion(); err == nil {
+		t.Errorf("GetVersion() with missing source should fail")
+	}
+}
+
 func BenchmarkGetList(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		GetList()
@@ -98,6 +118,155 @@ func TestMalformedTZData(t *testing.T) {
 	}
 }
 
+func TestPatch(t *testing.T) {
+	data, err := GetData("Europe/Athens")
+	if err != nil {
+		t.Fatalf("Error getting data: %s", err)
+	}
+
+	// 2030-03-31T01:00:00Z, start of DST
+	const start = 1901149200
+
+	patched := data.Clone()
+	if !patched.RemoveTransition(start) || patched.Equal(data) {
+		t.Fatalf("RemoveTransition(%d) did not remove transition", start)
+	}
+	if name, _, _, _ := patched.Lookup(start + 3600); name != "EET" {
+		t.Errorf("after RemoveTransition, got %q, want %q", name, "EET")
+	}
+	if err := patched.SetTransition(start, Era{Name: "EEST", Offset: 10800, IsDST: true}); err != nil {
+		t.Fatalf("SetTransition failed: %s", err)
+	}
+	if !patched.Equal(data) {
+		t.Errorf("SetTransition did not restore removed transition")
+	}
+
+	if err := patched.ReplaceRule(start, "EET-2"); err != nil {
+		t.Fatalf("ReplaceRule failed: %s", err)
+	}
+	if name, _, _, _ := patched.Lookup(start + 3600); name != "EET" || patched.Extend != "EET-2" {
+		t.Errorf("after ReplaceRule, got %q and extend %q", name, patched.Extend)
+	}
+	if last := patched.Trans[len(patched.Trans)-1].When; last >= start {
+		t.Errorf("after ReplaceRule, last transition at %d, want before %d", last, start)
+	}
+
+	// the same rule gives the same transitions
+	same := data.Clone()
+	if err := same.ReplaceRule(start, data.Extend); err != nil || !same.Equal(data) {
+		t.Errorf("ReplaceRule with same rule changed data (%v)", err)
+	}
+
+	if err := data.Clone().ReplaceRule(start, "X"); err == nil {
+		t.Errorf("ReplaceRule with invalid rule did not fail")
+	}
+}
+
+func TestIndicators(t *testing.T) {
+	data, err := GetData("Europe/Athens")
+	if err != nil {
+		t.Fatalf("Error getting data: %s", err)
+	}
+
+	// EU rules switch at 01:00 UT, so current eras carry both indicators
+	name, _, _, _ := data.Lookup(1593561600) // 2020-07-01
+	found := false
+	for _, era := range data.Eras {
+		if era.IsUT && !era.IsStd {
+			t.Errorf("era %+v is UT but not standard time", era)
+		}
+		found = found || (era.Name == name && era.IsUT)
+	}
+	if !found {
+		t.Errorf("no %s era with UT indicator in %+v", name, data.Eras)
+	}
+
+	// indicators must be given for all types or none: version 1 data
+	// with two types, but a single UT indicator
+	blob := "TZif\x00" + strings.Repeat("\x00", 15) +
+		"\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x00\x00\x04" +
+		"\x00\x00\x00\x00\x00\x00" + "\x00\x00\x0e\x10\x00\x00" + "UTC\x00" + "\x01"
+	if _, err := parseRawTZdata("bad", []byte(blob)); err == nil {
+		t.Errorf("expected error for partial indicators, got none")
+	}
+	if _, err := parseRawTZdata("good", []byte(strings.Replace(blob, "\x00\x00\x00\x01", "\x00\x00\x00\x00", 1)[:len(blob)-1])); err != nil {
+		t.Errorf("unexpected error without indicators: %s", err)
+	}
+}
+
+func TestClip(t *testing.T) {
+	data, err := GetData("Europe/Athens")
+	if err != nil {
+		t.Fatalf("Error getting data: %s", err)
+	}
+
+	// 1970-07-01T00:00:00Z, EET in effect
+	const since = 15638400
+
+	clipped := data.Clone()
+	if !clipped.Clip(since) {
+		t.Fatalf("Clip(%d) did not drop any transitions", since)
+	}
+	if first := clipped.Trans[0].When; first != since {
+		t.Errorf("after Clip, first transition at %d, want %d", first, since)
+	}
+	for _, sec := range []int64{since, since + 86400*365*10, since + 86400*365*60} {
+		name, offset, _, _ := clipped.Lookup(sec)
+		wantName, wantOffset, _, _ := data.Lookup(sec)
+		if name != wantName || offset != wantOffset {
+			t.Errorf("after Clip, Lookup(%d) = %s%+d, want %s%+d", sec, name, offset, wantName, wantOffset)
+		}
+	}
+	if clipped.Clip(since) {
+		t.Errorf("Clip(%d) of clipped data dropped transitions", since)
+	}
+}
+
+func TestGetZoneTab(t *testing.T) {
+	for _, tab := range []string{"zone.tab", "zone1970.tab"} {
+		entries, err := GetZoneTab(tab)
+		if err != nil {
+			t.Fatalf("GetZoneTab(%q) failed: %s", tab, err)
+		}
+
+		found := false
+		for _, entry := range entries {
+			if entry.Timezone == "Europe/Athens" {
+				found = entry.Countries[0] == "GR" && entry.Latitude > 37.9 && entry.Longitude < 23.8
+			}
+		}
+		if !found {
+			t.Errorf("GetZoneTab(%q) has no proper entry for Europe/Athens", tab)
+		}
+	}
+
+	countries, err := GetCountries()
+	if err != nil || countries["GR"] != "Greece" {
+		t.Errorf("GetCountries() = %q for GR, %v", countries["GR"], err)
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	for _, test := range []struct {
+		in       string
+		lat, lon float64
+		ok       bool
+	}{
+		{"+3758+02343", 37 + 58.0/60, 23 + 43.0/60, true},
+		{"-3352+15113", -(33 + 52.0/60), 151 + 13.0/60, true},
+		{"+404251-0740023", 40 + 42.0/60 + 51.0/3600, -(74 + 23.0/3600), true},
+		{"+3758", 0, 0, false},
+		{"", 0, 0, false},
+		{"+37580+02343", 0, 0, false},
+		{"+37x8+02343", 0, 0, false},
+	} {
+		lat, lon, ok := parseCoordinates(test.in)
+		if ok != test.ok || (ok && (math.Abs(lat-test.lat) > 1e-9 || math.Abs(lon-test.lon) > 1e-9)) {
+			t.Errorf("parseCoordinates(%q) = %v, %v, %t, want %v, %v, %t", test.in, lat, lon, ok, test.lat, test.lon, test.ok)
+		}
+	}
+}
+
 func TestTzset(t *testing.T) {
 	for _, test := range []struct {
 		inStr string
@@ -0,0 +1,149 @@
+package tzdb
+
+import (
+	"fmt"
+)
+
+// RebuildAbbreviations rebuilds the index of abbreviations from the zones
+// of all originals, as seen by readers. Consecutive zones of an original
+// with the same abbreviation and offset are merged into a single period.
+// The number of periods indexed is returned.
+func RebuildAbbreviations() (int, error) {
+	if !dbOpen {
+		return 0, noDB
+	}
+
+	originals, err := getOriginals()
+	if err != nil {
+		return 0, err
+	}
+
+	uses := make([]AbbreviationUse, 0, 30000)
+	for i := range originals {
+		zones, err := effectiveZones(&originals[i])
+		if err != nil {
+			return 0, err
+		}
+		uses = appendAbbreviationUses(uses, originals[i].Name, zones)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", abbrevTable)); err != nil {
+		return 0, err
+	}
+
+	fields := getAbbrevCols()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?, ?)",
+		abbrevTable, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6])
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, use := range uses {
+		if _, err := stmt.Exec(use.Abbrev, use.Timezone, use.Offset, use.IsDST, use.Start, use.End); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := bumpGeneration(tx); err != nil {
+		return 0, err
+	}
+
+	return len(uses), tx.Commit()
+}
+
+// appendAbbreviationUses appends the periods of use of abbreviations by a timezone.
+func appendAbbreviationUses(uses []AbbreviationUse, timezone string, zones []Zone) []AbbreviationUse {
+	first := len(uses)
+	for _, zone := range zones {
+		if last := len(uses) - 1; last >= first {
+			prev := &uses[last]
+			if prev.Abbrev == zone.Name && prev.Offset == zone.Offset && prev.IsDST == zone.IsDST && prev.End+1 == zone.Start {
+				prev.End = zone.End
+				continue
+			}
+		}
+		uses = append(uses, AbbreviationUse{Abbrev: zone.Name, Timezone: timezone,
+			Offset: zone.Offset, IsDST: zone.IsDST, Start: zone.Start, End: zone.End})
+	}
+	return uses
+}
+
+// GetByAbbreviation retrieves the timezones that used an abbreviation at
+// an instant, expressed in seconds since January 1, 1970 UTC, along with
+// the offset it stood for. Results are sorted by timezone.
+func GetByAbbreviation(abbrev string, sec int64) ([]AbbreviationUse, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	fields := getAbbrevCols()
+	return getAbbreviationUses(fmt.Sprintf("WHERE %s=? AND %s<=? AND (%s=-1 OR %s>=?) ORDER BY %s",
+		fields[1], fields[5], fields[6], fields[6], fields[2]), abbrev, sec, sec)
+}
+
+// GetAbbreviationUses retrieves all periods of use of an abbreviation,
+// sorted by timezone and start.
+func GetAbbreviationUses(abbrev string) ([]AbbreviationUse, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	fields := getAbbrevCols()
+	return getAbbreviationUses(fmt.Sprintf("WHERE %s=? ORDER BY %s, %s", fields[1], fields[2], fields[5]), abbrev)
+}
+
+// GetAbbreviationCount returns the number of periods in the index of abbreviations.
+func GetAbbreviationCount() (int, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return 0, noDB
+	}
+	if !tableDefined(abbrevTable) {
+		return 0, nil
+	}
+
+	var count int
+	err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", abbrevTable)).Scan(&count)
+	return count, err
+}
+
+// getAbbreviationUses retrieves the periods matching a clause.
+// Databases without an index of abbreviations have none.
+func getAbbreviationUses(clause string, args ...interface{}) ([]AbbreviationUse, error) {
+	uses := make([]AbbreviationUse, 0, 10)
+	if !tableDefined(abbrevTable) {
+		return uses, nil
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s %s", abbrevTable, clause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u AbbreviationUse
+		if err := rows.Scan(&u.ID, &u.Abbrev, &u.Timezone, &u.Offset, &u.IsDST, &u.Start, &u.End); err != nil {
+			return nil, err
+		}
+		uses = append(uses, u)
+	}
+
+	return uses, rows.Err()
+}
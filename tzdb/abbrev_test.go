@@ -0,0 +1,50 @@
+package tzdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAppendAbbreviationUses(t *testing.T) {
+	zones := []Zone{
+		{Name: "EET", Offset: 7200, Start: -1000, End: 99},
+		{Name: "EET", Offset: 7200, Start: 100, End: 199},
+		{Name: "EEST", Offset: 10800, IsDST: true, Start: 200, End: 299},
+		{Name: "EET", Offset: 7200, Start: 300, End: -1}}
+
+	uses := appendAbbreviationUses(nil, "Europe/Athens", zones)
+	if len(uses) != 3 {
+		t.Fatalf("appendAbbreviationUses returned %d periods, want 3: %+v", len(uses), uses)
+	}
+	if uses[0].Start != -1000 || uses[0].End != 199 || uses[2].End != -1 {
+		t.Errorf("appendAbbreviationUses merged periods wrongly: %+v", uses)
+	}
+}
+
+func TestGetByAbbreviation(t *testing.T) {
+	openTestDB(t, "Europe/Athens", "Asia/Nicosia")
+	if _, err := RebuildAbbreviations(); err != nil {
+		t.Fatalf("RebuildAbbreviations failed: %s", err)
+	}
+
+	summer := time.Date(2020, 7, 15, 12, 0, 0, 0, time.UTC).Unix()
+	uses, err := GetByAbbreviation("EEST", summer)
+	if err != nil {
+		t.Fatalf("GetByAbbreviation failed: %s", err)
+	}
+	found := false
+	for _, use := range uses {
+		if use.Offset != 10800 || !use.IsDST {
+			t.Errorf("EEST of %s stands for %+d (isdst=%v)", use.Timezone, use.Offset, use.IsDST)
+		}
+		found = found || use.Timezone == "Europe/Athens"
+	}
+	if !found {
+		t.Errorf("GetByAbbreviation(\"EEST\") = %+v, missing Europe/Athens", uses)
+	}
+
+	winter := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC).Unix()
+	if uses, err := GetByAbbreviation("EEST", winter); err != nil || len(uses) != 0 {
+		t.Errorf("GetByAbbreviation(\"EEST\") in winter = %+v, %v", uses, err)
+	}
+}
@@ -0,0 +1,66 @@
+package tzdb
+
+import (
+	"fmt"
+)
+
+// AddAudit records an entry in the table of audit entries.
+func AddAudit(entry AuditEntry) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	return addAudit(db, entry)
+}
+
+func addAudit(conn dbConn, entry AuditEntry) error {
+	fields := getAuditCols()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?)",
+		auditTable, fields[1], fields[2], fields[3], fields[4], fields[5])
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(entry.Time, entry.Action, entry.Source, entry.Timezone, entry.Detail)
+	return err
+}
+
+// GetAudit retrieves all audit entries, oldest first.
+// Databases without a table of audit entries have none.
+func GetAudit() (entries []AuditEntry, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	fields := getAuditCols()
+	query := fmt.Sprintf("SELECT name FROM sqlite_master WHERE type='table' AND name='%s'", auditTable)
+	var name string
+	if err := db.QueryRow(query).Scan(&name); err != nil {
+		return []AuditEntry{}, nil
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY %s", auditTable, fields[0])
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries = make([]AuditEntry, 0, 10)
+	for rows.Next() {
+		var e AuditEntry
+		err = rows.Scan(&e.ID, &e.Time, &e.Action, &e.Source, &e.Timezone, &e.Detail)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,170 @@
+package tzdb
+
+import (
+	"fmt"
+)
+
+// Problem describes a structural inconsistency of the database.
+type Problem struct {
+	Timezone string `json:"timezone,omitempty"`
+	Table    string `json:"table,omitempty"`
+	Kind     string `json:"kind"`
+	Detail   string `json:"detail"`
+}
+
+// Kinds of problems reported by Check.
+const (
+	ProblemIncomplete  = "incomplete-original"
+	ProblemNoTable     = "missing-table"
+	ProblemZones       = "inconsistent-zones"
+	ProblemDangling    = "dangling-replica"
+	ProblemUnreachable = "unreachable-original"
+	ProblemSequence    = "sequence-mismatch"
+)
+
+// Check validates the structural consistency of the open database.
+// Unlike GetZones, it only considers the table of zones that each
+// original points to and never falls back to older table versions.
+// An error is returned only if the database cannot be read at all.
+func Check() ([]Problem, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	originals, err := getOriginals()
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, err := getReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	problems := make([]Problem, 0)
+
+	// IDs should not exceed the sequence that new rows get theirs from
+	if detail, ok := checkSequence(originalTable, getOriginalCols()[0]); !ok {
+		problems = append(problems, Problem{Table: originalTable, Kind: ProblemSequence, Detail: detail})
+	}
+	if detail, ok := checkSequence(replicaTable, getReplicaCols()[0]); !ok {
+		problems = append(problems, Problem{Table: replicaTable, Kind: ProblemSequence, Detail: detail})
+	}
+
+	// every replica should point to an existing original
+	ids := make(map[int64]bool, len(originals))
+	for _, original := range originals {
+		ids[original.ID] = true
+	}
+	selfLinked := make(map[string]bool, len(originals))
+	for _, replica := range replicas {
+		if !ids[replica.ProtoID] {
+			problems = append(problems, Problem{Timezone: replica.Name, Kind: ProblemDangling,
+				Detail: fmt.Sprintf("links to missing original with ID %d", replica.ProtoID)})
+		}
+		selfLinked[replica.Name] = true
+	}
+
+	for _, original := range originals {
+		problems = append(problems, checkOriginal(original, selfLinked[original.Name])...)
+	}
+
+	return problems, nil
+}
+
+// checkOriginal validates a single original and its active table of zones.
+func checkOriginal(original Original, selfLinked bool) []Problem {
+	problems := make([]Problem, 0)
+
+	// timezones are always looked up through the table of replicas
+	if !selfLinked {
+		problems = append(problems, Problem{Timezone: original.Name, Kind: ProblemUnreachable,
+			Detail: "no replica with the name of the original"})
+	}
+
+	if original.TabName == "" || original.TZDVer == "" {
+		problems = append(problems, Problem{Timezone: original.Name, Kind: ProblemIncomplete,
+			Detail: "original was added but never updated"})
+		return problems
+	}
+
+	zoneTable := fmt.Sprintf("%s%v", original.TabName, original.TabVer)
+	if !tableExists(zoneTable) {
+		// originals without any transitions never get a table of zones
+		if original.TabVer != 0 {
+			problems = append(problems, Problem{Timezone: original.Name, Table: zoneTable, Kind: ProblemNoTable,
+				Detail: fmt.Sprintf("table of zones version %d does not exist", original.TabVer)})
+		}
+		return problems
+	}
+
+	zones, err := getZones(zoneTable)
+	if err != nil {
+		problems = append(problems, Problem{Timezone: original.Name, Table: zoneTable, Kind: ProblemNoTable,
+			Detail: err.Error()})
+		return problems
+	}
+
+	if detail, ok := checkSequence(zoneTable, getZoneCols()[0]); !ok {
+		problems = append(problems, Problem{Timezone: original.Name, Table: zoneTable, Kind: ProblemSequence,
+			Detail: detail})
+	}
+
+	for _, detail := range checkZones(zones) {
+		problems = append(problems, Problem{Timezone: original.Name, Table: zoneTable, Kind: ProblemZones,
+			Detail: detail})
+	}
+
+	return problems
+}
+
+// checkSequence validates that the largest ID stored in a table does not
+// exceed the last one handed out by SQLite, since rows added later would
+// otherwise collide with the stored ones.
+func checkSequence(table, column string) (string, bool) {
+	var maxID, seq int64
+	query := fmt.Sprintf("SELECT IFNULL(MAX(%q), 0) FROM %q", column, table)
+	if err := db.QueryRow(query).Scan(&maxID); err != nil {
+		return err.Error(), false
+	}
+	query = "SELECT IFNULL((SELECT seq FROM sqlite_sequence WHERE name=?), 0)"
+	if err := db.QueryRow(query, table).Scan(&seq); err != nil {
+		return err.Error(), false
+	}
+
+	if maxID > seq {
+		return fmt.Sprintf("largest ID %d exceeds sequence %d", maxID, seq), false
+	}
+	return "", true
+}
+
+// checkZones validates that zones are contiguous and that
+// only the last one extends to the end of time.
+func checkZones(zones []Zone) []string {
+	details := make([]string, 0)
+
+	for i, zone := range zones {
+		last := i == len(zones)-1
+		if last {
+			if zone.End != -1 {
+				details = append(details, fmt.Sprintf("last zone #%d ends at %d instead of -1", i, zone.End))
+			}
+			break
+		}
+
+		next := zones[i+1]
+		switch {
+		case zone.End == -1:
+			details = append(details, fmt.Sprintf("zone #%d is open-ended but is not the last one", i))
+		case zone.End < zone.Start:
+			details = append(details, fmt.Sprintf("zone #%d ends (%d) before it starts (%d)", i, zone.End, zone.Start))
+		case zone.End != next.Start-1:
+			details = append(details, fmt.Sprintf("zone #%d ends at %d but zone #%d starts at %d", i, zone.End, i+1, next.Start))
+		}
+	}
+
+	return details
+}
@@ -0,0 +1,25 @@
+package tzdb
+
+import (
+	"testing"
+)
+
+func TestCheckZones(t *testing.T) {
+	for _, test := range []struct {
+		zones    []Zone
+		problems int
+	}{
+		{[]Zone{}, 0},
+		{[]Zone{{Start: 100, End: -1}}, 0},
+		{[]Zone{{Start: 100, End: 199}, {Start: 200, End: -1}}, 0},
+		{[]Zone{{Start: 100, End: 199}, {Start: 200, End: 300}}, 1}, // last zone not open-ended
+		{[]Zone{{Start: 100, End: 150}, {Start: 200, End: -1}}, 1},  // gap between zones
+		{[]Zone{{Start: 100, End: -1}, {Start: 200, End: -1}}, 1},   // open-ended zone in the middle
+		{[]Zone{{Start: 100, End: 50}, {Start: 51, End: -1}}, 1},    // zone ends before it starts
+		{[]Zone{{Start: 100, End: 150}, {Start: 200, End: 300}}, 2}, // gap and closed last zone
+	} {
+		if details := checkZones(test.zones); len(details) != test.problems {
+			t.Errorf("checkZones(%v) reported %d problems, want %d: %v", test.zones, len(details), test.problems, details)
+		}
+	}
+}
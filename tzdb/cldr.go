@@ -0,0 +1,285 @@
+package tzdb
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CLDRNames holds the data of a CLDR file of timezone names: either the
+// usage of metazones (metaZones.xml or metaZones.json) or the display names
+// of a locale (main/{locale}.xml or main/{locale}/timeZoneNames.json).
+type CLDRNames struct {
+	Metazones []MetazoneUsage
+	Locale    string
+	Names     []ZoneNames
+}
+
+// cldrXML is the part of CLDR XML files that is needed,
+// whether the root element is supplementalData or ldml.
+type cldrXML struct {
+	XMLName  xml.Name
+	Identity struct {
+		Language  cldrTypeXML `xml:"language"`
+		Script    cldrTypeXML `xml:"script"`
+		Territory cldrTypeXML `xml:"territory"`
+		Variant   cldrTypeXML `xml:"variant"`
+	} `xml:"identity"`
+	Timezones []struct {
+		Type string `xml:"type,attr"`
+		Uses []struct {
+			Metazone string `xml:"mzone,attr"`
+			From     string `xml:"from,attr"`
+			To       string `xml:"to,attr"`
+		} `xml:"usesMetazone"`
+	} `xml:"metaZones>metazoneInfo>timezone"`
+	Zones     []cldrNamesXML `xml:"dates>timeZoneNames>zone"`
+	Metazones []cldrNamesXML `xml:"dates>timeZoneNames>metazone"`
+}
+
+type cldrTypeXML struct {
+	Type string `xml:"type,attr"`
+}
+
+type cldrNamesXML struct {
+	Type  string       `xml:"type,attr"`
+	Long  cldrFormsXML `xml:"long"`
+	Short cldrFormsXML `xml:"short"`
+}
+
+type cldrFormsXML struct {
+	Generic  string `xml:"generic"`
+	Standard string `xml:"standard"`
+	Daylight string `xml:"daylight"`
+}
+
+// ReadCLDRNames reads a CLDR file of timezone names, in XML or JSON format.
+func ReadCLDRNames(r io.Reader) (*CLDRNames, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); string(bom) == "\xEF\xBB\xBF" {
+		br.Discard(3)
+	}
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, errors.New("tzdb: empty CLDR file")
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			break
+		}
+		br.ReadByte()
+	}
+
+	var names *CLDRNames
+	var err error
+	if b, _ := br.Peek(1); b[0] == '{' {
+		names, err = readCLDRJSON(br)
+	} else {
+		names, err = readCLDRXML(br)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names.Metazones) == 0 && len(names.Names) == 0 {
+		return nil, errors.New("tzdb: no metazones or timezone names found")
+	}
+
+	sort.SliceStable(names.Metazones, func(i, j int) bool {
+		return names.Metazones[i].Timezone < names.Metazones[j].Timezone
+	})
+	return names, nil
+}
+
+func readCLDRXML(r io.Reader) (*CLDRNames, error) {
+	var doc cldrXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("tzdb: cannot parse CLDR file: %s", err)
+	}
+
+	names := &CLDRNames{}
+	for _, tz := range doc.Timezones {
+		for _, use := range tz.Uses {
+			usage, err := newMetazoneUsage(tz.Type, use.Metazone, use.From, use.To)
+			if err != nil {
+				return nil, err
+			}
+			names.Metazones = append(names.Metazones, usage)
+		}
+	}
+
+	if doc.XMLName.Local != "ldml" {
+		return names, nil
+	}
+
+	id := doc.Identity
+	parts := []string{id.Language.Type, id.Script.Type, id.Territory.Type, id.Variant.Type}
+	for _, part := range parts {
+		if part != "" {
+			names.Locale += "_" + part
+		}
+	}
+	names.Locale = strings.TrimPrefix(names.Locale, "_")
+
+	for kind, list := range map[string][]cldrNamesXML{"zone": doc.Zones, "metazone": doc.Metazones} {
+		for _, n := range list {
+			zn := ZoneNames{Locale: names.Locale, Kind: kind, Name: n.Type,
+				LongGeneric: n.Long.Generic, LongStandard: n.Long.Standard, LongDaylight: n.Long.Daylight,
+				ShortGeneric: n.Short.Generic, ShortStandard: n.Short.Standard, ShortDaylight: n.Short.Daylight}
+			if zn.hasNames() {
+				names.Names = append(names.Names, zn)
+			}
+		}
+	}
+	sortZoneNames(names.Names)
+
+	return names, nil
+}
+
+func readCLDRJSON(r io.Reader) (*CLDRNames, error) {
+	var doc map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("tzdb: cannot parse CLDR file: %s", err)
+	}
+
+	names := &CLDRNames{}
+	if info := jsonPath(doc, "supplemental", "metaZones", "metazoneInfo", "timezone"); info != nil {
+		var firstErr error
+		walkCLDRJSON(info, "", func(tz string, node interface{}) bool {
+			uses, ok := node.([]interface{})
+			if !ok {
+				return false
+			}
+			for _, item := range uses {
+				use, _ := jsonPath(item, "usesMetazone").(map[string]interface{})
+				metazone, _ := use["_mzone"].(string)
+				from, _ := use["_from"].(string)
+				to, _ := use["_to"].(string)
+				usage, err := newMetazoneUsage(tz, metazone, from, to)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				names.Metazones = append(names.Metazones, usage)
+			}
+			return true
+		})
+		if firstErr != nil {
+			return nil, firstErr
+		}
+	}
+
+	main, _ := doc["main"].(map[string]interface{})
+	if len(main) > 1 {
+		return nil, errors.New("tzdb: more than one locale in CLDR file")
+	}
+	for locale, data := range main {
+		names.Locale = strings.Replace(locale, "-", "_", -1)
+		tzNames := jsonPath(data, "dates", "timeZoneNames")
+
+		// timezones are nested by the parts of their names
+		walkCLDRJSON(jsonPath(tzNames, "zone"), "", func(tz string, node interface{}) bool {
+			fields, ok := node.(map[string]interface{})
+			if !ok || (fields["long"] == nil && fields["short"] == nil && fields["exemplarCity"] == nil) {
+				return false
+			}
+			if zn := newZoneNamesJSON(names.Locale, "zone", tz, node); zn.hasNames() {
+				names.Names = append(names.Names, zn)
+			}
+			return true
+		})
+
+		metazones, _ := jsonPath(tzNames, "metazone").(map[string]interface{})
+		for metazone, node := range metazones {
+			if zn := newZoneNamesJSON(names.Locale, "metazone", metazone, node); zn.hasNames() {
+				names.Names = append(names.Names, zn)
+			}
+		}
+	}
+	sortZoneNames(names.Names)
+
+	return names, nil
+}
+
+// walkCLDRJSON visits the nodes of a tree of JSON objects keyed by the parts
+// of timezone names, until visit reports that a node is a leaf.
+func walkCLDRJSON(node interface{}, name string, visit func(name string, node interface{}) bool) {
+	if name != "" && visit(name, node) {
+		return
+	}
+	children, _ := node.(map[string]interface{})
+	for key, child := range children {
+		if name != "" {
+			key = name + "/" + key
+		}
+		walkCLDRJSON(child, key, visit)
+	}
+}
+
+// jsonPath retrieves the value found under a path of keys, if any.
+func jsonPath(node interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = object[key]
+	}
+	return node
+}
+
+func newZoneNamesJSON(locale, kind, name string, node interface{}) ZoneNames {
+	form := func(length, form string) string {
+		value, _ := jsonPath(node, length, form).(string)
+		return value
+	}
+	return ZoneNames{Locale: locale, Kind: kind, Name: name,
+		LongGeneric: form("long", "generic"), LongStandard: form("long", "standard"), LongDaylight: form("long", "daylight"),
+		ShortGeneric: form("short", "generic"), ShortStandard: form("short", "standard"), ShortDaylight: form("short", "daylight")}
+}
+
+// newMetazoneUsage converts the usage of a metazone, as given by CLDR,
+// where instants are UTC times in the form 1981-03-28 22:00.
+func newMetazoneUsage(timezone, metazone, from, to string) (MetazoneUsage, error) {
+	usage := MetazoneUsage{Timezone: timezone, Metazone: metazone, From: math.MinInt64, To: -1}
+	if timezone == "" || metazone == "" {
+		return usage, fmt.Errorf("tzdb: incomplete usage of metazone %q by %q", metazone, timezone)
+	}
+
+	for _, instant := range []struct {
+		value string
+		sec   *int64
+	}{{from, &usage.From}, {to, &usage.To}} {
+		if instant.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02 15:04", instant.value)
+		if err != nil {
+			return usage, fmt.Errorf("tzdb: bad instant %q in usage of metazone %q by %q", instant.value, metazone, timezone)
+		}
+		*instant.sec = t.Unix()
+	}
+	return usage, nil
+}
+
+func (n ZoneNames) hasNames() bool {
+	return n.LongGeneric != "" || n.LongStandard != "" || n.LongDaylight != "" ||
+		n.ShortGeneric != "" || n.ShortStandard != "" || n.ShortDaylight != ""
+}
+
+// sortZoneNames sorts names by kind and name, so that
+// they are stored in the same order from XML and JSON.
+func sortZoneNames(names []ZoneNames) {
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].Kind != names[j].Kind {
+			return names[i].Kind < names[j].Kind
+		}
+		return names[i].Name < names[j].Name
+	})
+}
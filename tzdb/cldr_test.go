@@ -0,0 +1,135 @@
+package tzdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMetaZonesXML = `<?xml version="1.0" encoding="UTF-8" ?>
+<supplementalData>
+	<metaZones>
+		<metazoneInfo>
+			<timezone type="Europe/Athens">
+				<usesMetazone mzone="Europe_Eastern"/>
+			</timezone>
+			<timezone type="Europe/London">
+				<usesMetazone to="1971-10-31 02:00" mzone="Europe_Central"/>
+				<usesMetazone from="1971-10-31 02:00" mzone="GMT"/>
+			</timezone>
+		</metazoneInfo>
+	</metaZones>
+</supplementalData>
+`
+
+const testNamesXML = `<?xml version="1.0" encoding="UTF-8" ?>
+<ldml>
+	<identity>
+		<language type="en"/>
+	</identity>
+	<dates>
+		<timeZoneNames>
+			<zone type="Europe/London">
+				<long>
+					<daylight>British Summer Time</daylight>
+				</long>
+			</zone>
+			<metazone type="Europe_Eastern">
+				<long>
+					<generic>Eastern European Time</generic>
+					<standard>Eastern European Standard Time</standard>
+					<daylight>Eastern European Summer Time</daylight>
+				</long>
+			</metazone>
+			<metazone type="GMT">
+				<long>
+					<standard>Greenwich Mean Time</standard>
+				</long>
+				<short>
+					<standard>GMT</standard>
+				</short>
+			</metazone>
+		</timeZoneNames>
+	</dates>
+</ldml>
+`
+
+const testNamesJSON = `{"main": {"en-GB": {"identity": {"language": "en", "territory": "GB"},
+	"dates": {"timeZoneNames": {
+		"zone": {"Europe": {"London": {"short": {"daylight": "BST"}}}},
+		"metazone": {"Europe_Central": {"long": {"standard": "Central European Standard Time"}}}}}}}}`
+
+const testMetaZonesJSON = `{"supplemental": {"metaZones": {"metazoneInfo": {"timezone": {
+	"Europe": {"Athens": [{"usesMetazone": {"_mzone": "Europe_Eastern"}}]}}}}}}`
+
+func TestReadCLDRNames(t *testing.T) {
+	meta, err := ReadCLDRNames(strings.NewReader(testMetaZonesXML))
+	if err != nil || len(meta.Metazones) != 3 || meta.Metazones[1].To != meta.Metazones[2].From || meta.Metazones[2].To != -1 {
+		t.Errorf("ReadCLDRNames(metaZones.xml) = %+v, %v", meta, err)
+	}
+
+	metaJSON, err := ReadCLDRNames(strings.NewReader(testMetaZonesJSON))
+	if err != nil || len(metaJSON.Metazones) != 1 || metaJSON.Metazones[0] != meta.Metazones[0] {
+		t.Errorf("ReadCLDRNames(metaZones.json) = %+v, %v", metaJSON, err)
+	}
+
+	names, err := ReadCLDRNames(strings.NewReader(testNamesXML))
+	if err != nil || names.Locale != "en" || len(names.Names) != 3 || names.Names[0].Kind != "metazone" {
+		t.Errorf("ReadCLDRNames(en.xml) = %+v, %v", names, err)
+	}
+
+	namesJSON, err := ReadCLDRNames(strings.NewReader(testNamesJSON))
+	if err != nil || namesJSON.Locale != "en_GB" || len(namesJSON.Names) != 2 || namesJSON.Names[1].Name != "Europe/London" {
+		t.Errorf("ReadCLDRNames(timeZoneNames.json) = %+v, %v", namesJSON, err)
+	}
+
+	if _, err := ReadCLDRNames(strings.NewReader(`{"main": {}}`)); err == nil {
+		t.Errorf("ReadCLDRNames of file without names did not fail")
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	openTestDB(t, "Europe/Athens", "Europe/London")
+
+	meta, _ := ReadCLDRNames(strings.NewReader(testMetaZonesXML))
+	names, _ := ReadCLDRNames(strings.NewReader(testNamesXML))
+	namesGB, _ := ReadCLDRNames(strings.NewReader(testNamesJSON))
+	if err := SetMetazones(meta.Metazones); err != nil {
+		t.Fatalf("SetMetazones failed: %s", err)
+	}
+	for _, n := range []*CLDRNames{names, namesGB} {
+		if err := SetZoneNames(n.Locale, n.Names); err != nil {
+			t.Fatalf("SetZoneNames failed: %s", err)
+		}
+	}
+
+	winter := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC).Unix()
+	summer := time.Date(2020, 7, 15, 12, 0, 0, 0, time.UTC).Unix()
+	for _, test := range []struct {
+		timezone, locale string
+		sec              int64
+		short            bool
+		name             string
+	}{
+		{"Europe/Athens", "en", winter, false, "Eastern European Standard Time"},
+		{"Europe/Athens", "en", summer, false, "Eastern European Summer Time"},
+		{"Europe/Athens", "en-GB", summer, false, "Eastern European Summer Time"}, // from parent locale
+		{"Europe/London", "en", winter, false, "Greenwich Mean Time"},             // from metazone
+		{"Europe/London", "en", summer, false, "British Summer Time"},             // from timezone
+		{"Europe/London", "en_GB", winter, true, "GMT"},
+		{"Europe/London", "en_GB", summer, true, "BST"},
+		{"Europe/Athens", "en_GB", summer, true, "Eastern European Summer Time"}, // no short name
+	} {
+		display := DisplayName
+		if test.short {
+			display = ShortDisplayName
+		}
+		if name, err := display(test.timezone, test.locale, test.sec); err != nil || name != test.name {
+			t.Errorf("DisplayName(%q, %q, %d, short=%t) = %q, %v, want %q", test.timezone, test.locale, test.sec, test.short, name, err, test.name)
+		}
+	}
+
+	if _, err := DisplayName("Europe/Athens", "el", summer); err == nil {
+		t.Errorf("DisplayName for locale without names did not fail")
+	}
+}
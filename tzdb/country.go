@@ -0,0 +1,210 @@
+package tzdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// earthRadius is the mean radius of the earth in kilometers.
+const earthRadius = 6371.0
+
+// SetCountryZones replaces the stored countries and timezones per country.
+func SetCountryZones(countries []Country, zones []CountryZone) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{countryTable, countryZoneTable} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+			return err
+		}
+	}
+
+	fields := getCountryCols()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES(?, ?)", countryTable, fields[0], fields[1])
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, country := range countries {
+		if _, err := stmt.Exec(country.Code, country.Name); err != nil {
+			return err
+		}
+	}
+
+	fields = getCountryZoneCols()
+	query = fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?)",
+		countryZoneTable, fields[1], fields[2], fields[3], fields[4], fields[5])
+	zoneStmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer zoneStmt.Close()
+
+	for _, zone := range zones {
+		if _, err := zoneStmt.Exec(zone.Timezone, zone.Country, zone.Latitude, zone.Longitude, zone.Comment); err != nil {
+			return err
+		}
+	}
+
+	if err := bumpGeneration(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetCountries retrieves all countries, sorted by code.
+func GetCountries() ([]Country, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	countries := make([]Country, 0, 250)
+	if !tableDefined(countryTable) {
+		return countries, nil
+	}
+
+	fields := getCountryCols()
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s ORDER BY %s", countryTable, fields[0]))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var country Country
+		if err := rows.Scan(&country.Code, &country.Name); err != nil {
+			return nil, err
+		}
+		countries = append(countries, country)
+	}
+
+	return countries, rows.Err()
+}
+
+// GetZonesByCountry retrieves the timezones used in a country, specified by
+// its ISO 3166 alpha-2 code (e.g. "GR"), in the order of zone.tab: the most
+// populous timezones come first.
+func GetZonesByCountry(code string) ([]CountryZone, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	fields := getCountryZoneCols()
+	return getCountryZones(fmt.Sprintf("WHERE %s=? ORDER BY %s", fields[2], fields[0]), strings.ToUpper(code))
+}
+
+// GetCountryZones retrieves the countries a timezone is used in, with the
+// coordinates of its principal location. Replicas not listed in zone.tab
+// get the entries of the original they link to.
+func GetCountryZones(timezone string) ([]CountryZone, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	fields := getCountryZoneCols()
+	zones, err := getCountryZones(fmt.Sprintf("WHERE %s=? ORDER BY %s", fields[1], fields[0]), timezone)
+	if err != nil || len(zones) != 0 {
+		return zones, err
+	}
+
+	original, err := getOriginal(timezone)
+	if err != nil {
+		return nil, err
+	}
+	return getCountryZones(fmt.Sprintf("WHERE %s=? ORDER BY %s", fields[1], fields[0]), original.Name)
+}
+
+// NearestZone finds the timezone whose principal location is the nearest
+// to the specified coordinates (in degrees, positive north and east).
+// The distance to that location is returned in kilometers.
+func NearestZone(latitude, longitude float64) (zone CountryZone, distance float64, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return CountryZone{}, 0, noDB
+	}
+
+	zones, err := getCountryZones("")
+	if err != nil {
+		return CountryZone{}, 0, err
+	}
+	if len(zones) == 0 {
+		return CountryZone{}, 0, errors.New("tzdb: no coordinates of timezones stored")
+	}
+
+	distance = math.Inf(1)
+	for _, z := range zones {
+		if d := greatCircle(latitude, longitude, z.Latitude, z.Longitude); d < distance {
+			zone, distance = z, d
+		}
+	}
+	return zone, distance, nil
+}
+
+// getCountryZones retrieves the timezones per country matching a clause,
+// with placeholders for the specified arguments. Databases without a table
+// of timezones per country have none.
+func getCountryZones(clause string, args ...interface{}) ([]CountryZone, error) {
+	zones := make([]CountryZone, 0, 10)
+	if !tableDefined(countryZoneTable) {
+		return zones, nil
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s %s", countryZoneTable, clause), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var z CountryZone
+		if err := rows.Scan(&z.ID, &z.Timezone, &z.Country, &z.Latitude, &z.Longitude, &z.Comment); err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+
+	return zones, rows.Err()
+}
+
+// tableDefined checks whether a table exists, even if it is empty
+// or has no autoincrement key, unlike tableExists.
+func tableDefined(tableName string) bool {
+	var name string
+	query := fmt.Sprintf("SELECT name FROM sqlite_master WHERE type='table' AND name='%s'", tableName)
+	return db.QueryRow(query).Scan(&name) == nil
+}
+
+// greatCircle returns the distance in kilometers between two points,
+// given in degrees, using the haversine formula.
+func greatCircle(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dlat := (lat2 - lat1) * rad
+	dlon := (lon2 - lon1) * rad
+
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,45 @@
+package tzdb
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGreatCircle(t *testing.T) {
+	for _, test := range []struct {
+		lat1, lon1, lat2, lon2 float64
+		km                     float64
+	}{
+		{37.9667, 23.7167, 37.9667, 23.7167, 0},
+		{37.9667, 23.7167, 40.6403, 22.9439, 304.6}, // Athens - Thessaloniki
+		{0, 0, 0, 180, math.Pi * earthRadius},       // half the equator
+		{90, 0, -90, 0, math.Pi * earthRadius},      // pole to pole
+	} {
+		if km := greatCircle(test.lat1, test.lon1, test.lat2, test.lon2); math.Abs(km-test.km) > 1 {
+			t.Errorf("greatCircle(%v, %v, %v, %v) = %.1f, want %.1f", test.lat1, test.lon1, test.lat2, test.lon2, km, test.km)
+		}
+	}
+}
+
+func TestGetZonesByCountry(t *testing.T) {
+	openTestDB(t, "Asia/Jakarta")
+
+	// "ID" and "name" are also the names of columns
+	err := SetCountryZones([]Country{{"ID", "Indonesia"}}, []CountryZone{
+		{Timezone: "Asia/Jakarta", Country: "ID", Latitude: -6.1667, Longitude: 106.8}})
+	if err != nil {
+		t.Fatalf("SetCountryZones failed: %s", err)
+	}
+
+	for _, code := range []string{"ID", "id"} {
+		if zones, err := GetZonesByCountry(code); err != nil || len(zones) != 1 || zones[0].Timezone != "Asia/Jakarta" {
+			t.Errorf("GetZonesByCountry(%q) = %v, %v, want Asia/Jakarta", code, zones, err)
+		}
+	}
+	if zones, err := GetCountryZones("Asia/Jakarta"); err != nil || len(zones) != 1 || zones[0].Country != "ID" {
+		t.Errorf("GetCountryZones(%q) = %v, %v, want ID", "Asia/Jakarta", zones, err)
+	}
+	if zones, err := GetZonesByCountry("name"); err != nil || len(zones) != 0 {
+		t.Errorf("GetZonesByCountry(%q) = %v, %v, want none", "name", zones, err)
+	}
+}
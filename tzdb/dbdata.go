@@ -14,6 +14,12 @@ type Original struct {
 	TabName string
 	TabVer  int64
 	TZDVer  string // Version of TZ-data used to update sqlite database
+	Footer  string // TZ string for instants after the last zone, if any
+	Clipped bool   // Are zones before Since missing?
+	Since   int64  // Zones before this instant were dropped, if Clipped
+
+	Shared      bool  // Are zones before SharedSince those of another original?
+	SharedSince int64 // Zones were compared since this instant, if Shared
 }
 
 // Replica defines a link to some timezone
@@ -31,11 +37,108 @@ type Zone struct {
 	End    int64
 	Offset int64
 	IsDST  bool
+	IsStd  bool // standard time indicator of the local time type (RFC 8536)
+	IsUT   bool // UT indicator of the local time type (RFC 8536)
+}
+
+// AuditEntry records a change made to the database by other means than
+// the regular update from tzdata, e.g. an import.
+type AuditEntry struct {
+	ID       int64
+	Time     int64  // seconds since January 1, 1970 UTC
+	Action   string // e.g. import
+	Source   string // where the change came from, e.g. name of file
+	Timezone string // original timezone affected
+	Detail   string
+}
+
+// Override records a patch of the data of a timezone, applied by the
+// generator on top of tzdata, e.g. for a change announced on short notice.
+type Override struct {
+	ID         int64
+	Name       string // unique name of override, as given in the overrides file
+	Timezone   string // original timezone patched
+	Definition string // override, as given in the overrides file (JSON)
+	TZDVer     string // version of tzdata the override was last applied to
+	Status     string // applied, or superseded if tzdata already has the change
+	Time       int64  // seconds since January 1, 1970 UTC, when last applied
+}
+
+// Country defines a country, as listed in iso3166.tab.
+type Country struct {
+	Code string // ISO 3166 alpha-2 code
+	Name string
+}
+
+// CountryZone defines a timezone used in a country, as listed in zone.tab
+// and zone1970.tab, along with the coordinates of its principal location.
+type CountryZone struct {
+	ID        int64
+	Timezone  string // original or replica
+	Country   string // ISO 3166 alpha-2 code
+	Latitude  float64
+	Longitude float64
+	Comment   string
+}
+
+// WindowsZone maps a Windows timezone ID, within a territory,
+// to timezones, as listed in windowsZones.xml of CLDR.
+type WindowsZone struct {
+	ID        int64
+	WindowsID string   // e.g. GTB Standard Time
+	Territory string   // ISO 3166 alpha-2 code, or 001 for the default of the Windows ID
+	Timezones []string // first one is the preferred one
+}
+
+// MetazoneUsage records that a timezone used a metazone of CLDR (e.g. Europe_Central),
+// a group of timezones sharing display names, over a period of time.
+type MetazoneUsage struct {
+	ID       int64
+	Timezone string // as named by CLDR
+	Metazone string
+	From     int64 // seconds since January 1, 1970 UTC, math.MinInt64 if always used before
+	To       int64 // end of period (exclusive), -1 if still in use
+}
+
+// ZoneNames holds the localized display names of a metazone
+// or a single timezone, as given by CLDR for a locale.
+type ZoneNames struct {
+	ID            int64
+	Locale        string // e.g. en or en_GB
+	Kind          string // metazone or zone
+	Name          string // name of metazone or timezone
+	LongGeneric   string // e.g. Central European Time
+	LongStandard  string // e.g. Central European Standard Time
+	LongDaylight  string // e.g. Central European Summer Time
+	ShortGeneric  string
+	ShortStandard string
+	ShortDaylight string
+}
+
+// AbbreviationUse records a period during which an original timezone
+// used an abbreviation, along with the offset it stood for.
+type AbbreviationUse struct {
+	ID       int64
+	Abbrev   string
+	Timezone string // original timezone
+	Offset   int64
+	IsDST    bool
+	Start    int64 // as in Zone
+	End      int64 // as in Zone, -1 if still in use
 }
 
 const (
-	originalTable string = "original"
-	replicaTable  string = "replica"
+	originalTable    string = "original"
+	replicaTable     string = "replica"
+	auditTable       string = "audit"
+	overrideTable    string = "override"
+	countryTable     string = "country"
+	countryZoneTable string = "country_zone"
+	windowsTable     string = "windows_zone"
+	metazoneTable    string = "metazone_usage"
+	zoneNamesTable   string = "zone_names"
+	abbrevTable      string = "abbreviation"
+	generationTable  string = "generation"
 )
 
 // column names for table of prototypes
@@ -47,7 +150,10 @@ func getOriginalCols() []string {
 		"default_offset",
 		"zones_tab_name",
 		"zones_tab_ver",
-		"tzdada_ver"}
+		"tzdada_ver",
+		"footer",
+		"clipped_since",
+		"shared_since"}
 }
 
 // column names for table of replicas
@@ -58,6 +164,101 @@ func getReplicaCols() []string {
 		"original_id"}
 }
 
+// column names for table of audit entries
+func getAuditCols() []string {
+	return []string{
+		"id",
+		"time",
+		"action",
+		"source",
+		"timezone",
+		"detail"}
+}
+
+// column names for table of overrides
+func getOverrideCols() []string {
+	return []string{
+		"id",
+		"name",
+		"timezone",
+		"definition",
+		"tzdada_ver",
+		"status",
+		"time"}
+}
+
+// column names for table of countries
+func getCountryCols() []string {
+	return []string{
+		"code",
+		"name"}
+}
+
+// column names for table of timezones per country
+func getCountryZoneCols() []string {
+	return []string{
+		"id",
+		"timezone",
+		"country",
+		"latitude",
+		"longitude",
+		"comment"}
+}
+
+// column names for table of Windows timezone IDs
+func getWindowsCols() []string {
+	return []string{
+		"id",
+		"windows_id",
+		"territory",
+		"timezones"}
+}
+
+// column names for table of usage of metazones
+func getMetazoneCols() []string {
+	return []string{
+		"id",
+		"timezone",
+		"metazone",
+		"from_time",
+		"to_time"}
+}
+
+// column names for table of display names
+func getZoneNamesCols() []string {
+	return []string{
+		"id",
+		"locale",
+		"kind",
+		"name",
+		"long_generic",
+		"long_standard",
+		"long_daylight",
+		"short_generic",
+		"short_standard",
+		"short_daylight"}
+}
+
+// column names for index of abbreviations
+func getAbbrevCols() []string {
+	return []string{
+		"id",
+		"abbrev",
+		"timezone",
+		"offset",
+		"is_dst",
+		"start",
+		"end"}
+}
+
+// column names for table of the generation of data,
+// which holds a single row
+func getGenerationCols() []string {
+	return []string{
+		"id",
+		"value"}
+}
+
 // column names for each tables of zones
 func getZoneCols() []string {
 	return []string{
@@ -66,19 +267,32 @@ func getZoneCols() []string {
 		"start",
 		"end",
 		"offset",
-		"is_dst"}
+		"is_dst",
+		"is_std",
+		"is_ut"}
 }
 
 // column names for table of prototypes
 func getOriginalSchema() string {
 	fields := getOriginalCols()
 
-	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL UNIQUE, %q TEXT DEFAULT \"\", %q INTEGER DEFAULT 0, %q TEXT DEFAULT \"\", %q INTEGER DEFAULT 0, %q TEXT DEFAULT \"\", PRIMARY KEY(%q AUTOINCREMENT));",
-		originalTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[0])
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL UNIQUE, %q TEXT DEFAULT \"\", %q INTEGER DEFAULT 0, %q TEXT DEFAULT \"\", %q INTEGER DEFAULT 0, %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q INTEGER DEFAULT NULL, %q INTEGER DEFAULT NULL, PRIMARY KEY(%q AUTOINCREMENT));",
+		originalTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7], fields[8], fields[9], fields[0])
 
 	return schema
 }
 
+// statements that bring the table of prototypes of older
+// databases up to date, keyed by the column they add
+func getOriginalMigrations() map[string]string {
+	fields := getOriginalCols()
+
+	return map[string]string{
+		fields[7]: fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q TEXT DEFAULT \"\";", originalTable, fields[7]),
+		fields[8]: fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q INTEGER DEFAULT NULL;", originalTable, fields[8]),
+		fields[9]: fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q INTEGER DEFAULT NULL;", originalTable, fields[9])}
+}
+
 // column names for table of replicas
 func getReplicaSchema() string {
 	fields := getReplicaCols()
@@ -90,12 +304,119 @@ func getReplicaSchema() string {
 	return schema
 }
 
+// column names for table of audit entries
+func getAuditSchema() string {
+	fields := getAuditCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q INTEGER NOT NULL, %q TEXT NOT NULL, %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", PRIMARY KEY(%q AUTOINCREMENT));",
+		auditTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[0])
+
+	return schema
+}
+
+// column names for table of overrides
+func getOverrideSchema() string {
+	fields := getOverrideCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL UNIQUE, %q TEXT NOT NULL, %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q INTEGER DEFAULT 0, PRIMARY KEY(%q AUTOINCREMENT));",
+		overrideTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[0])
+
+	return schema
+}
+
+// column names for table of countries
+func getCountrySchema() string {
+	fields := getCountryCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q TEXT NOT NULL UNIQUE, %q TEXT DEFAULT \"\", PRIMARY KEY(%q));",
+		countryTable, fields[0], fields[1], fields[0])
+
+	return schema
+}
+
+// column names for table of timezones per country
+func getCountryZoneSchema() string {
+	fields := getCountryZoneCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL, %q TEXT NOT NULL, %q REAL DEFAULT 0, %q REAL DEFAULT 0, %q TEXT DEFAULT \"\", PRIMARY KEY(%q AUTOINCREMENT));",
+		countryZoneTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[0])
+
+	return schema
+}
+
+// column names for table of Windows timezone IDs
+func getWindowsSchema() string {
+	fields := getWindowsCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL, %q TEXT NOT NULL, %q TEXT NOT NULL, PRIMARY KEY(%q AUTOINCREMENT));",
+		windowsTable, fields[0], fields[1], fields[2], fields[3], fields[0])
+
+	return schema
+}
+
+// column names for table of usage of metazones
+func getMetazoneSchema() string {
+	fields := getMetazoneCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL, %q TEXT NOT NULL, %q INTEGER NOT NULL, %q INTEGER NOT NULL, PRIMARY KEY(%q AUTOINCREMENT));",
+		metazoneTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[0])
+
+	return schema
+}
+
+// column names for table of display names
+func getZoneNamesSchema() string {
+	fields := getZoneNamesCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL, %q TEXT NOT NULL, %q TEXT NOT NULL, %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", %q TEXT DEFAULT \"\", PRIMARY KEY(%q AUTOINCREMENT));",
+		zoneNamesTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7], fields[8], fields[9], fields[0])
+
+	return schema
+}
+
+// column names for index of abbreviations
+func getAbbrevSchema() string {
+	fields := getAbbrevCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT NOT NULL, %q TEXT NOT NULL, %q INTEGER NOT NULL, %q INTEGER, %q INTEGER, %q INTEGER, PRIMARY KEY(%q AUTOINCREMENT));",
+		abbrevTable, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[0])
+
+	return schema
+}
+
+// index of abbreviations by abbreviation
+func getAbbrevIndexSchema() string {
+	fields := getAbbrevCols()
+
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS \"%s_%s\" ON %q (%q);", abbrevTable, fields[1], abbrevTable, fields[1])
+}
+
+// column names for table of the generation of data
+func getGenerationSchema() string {
+	fields := getGenerationCols()
+
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q INTEGER NOT NULL, PRIMARY KEY(%q));",
+		generationTable, fields[0], fields[1], fields[0])
+
+	return schema
+}
+
 // column names for each tables of zones
 func getZoneSchema(name string) string {
 	fields := getZoneCols()
 
-	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT DEFAULT \"\", %q INTEGER, %q INTEGER, %q INTEGER NOT NULL, %q INTEGER, PRIMARY KEY(%q AUTOINCREMENT));",
-		name, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[0])
+	schema := fmt.Sprintf("CREATE TABLE %q (%q INTEGER UNIQUE, %q TEXT DEFAULT \"\", %q INTEGER, %q INTEGER, %q INTEGER NOT NULL, %q INTEGER, %q INTEGER DEFAULT 0, %q INTEGER DEFAULT 0, PRIMARY KEY(%q AUTOINCREMENT));",
+		name, fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7], fields[0])
 
 	return schema
 }
+
+// statements that bring tables of zones of older
+// databases up to date, keyed by the column they add
+func getZoneMigrations(name string) map[string]string {
+	fields := getZoneCols()
+
+	return map[string]string{
+		fields[6]: fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q INTEGER DEFAULT 0;", name, fields[6]),
+		fields[7]: fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q INTEGER DEFAULT 0;", name, fields[7])}
+}
@@ -0,0 +1,195 @@
+package tzdb
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DedupStats describes the outcome of DedupZones.
+type DedupStats struct {
+	Groups int   // sets of zones shared by more than one original
+	Shared int   // originals pointed to the table of zones of another one
+	Tables int   // tables of zones dropped
+	Zones  int   // zones of the tables dropped
+	Bytes  int64 // space saved in the database file
+}
+
+// SharesZones reports whether the original points to the table
+// of zones of another original, as set by DedupZones.
+func (o *Original) SharesZones() bool {
+	name, err := makeTabName(o.Name)
+	return err == nil && o.TabName != "" && o.TabName != name
+}
+
+// DedupZones finds originals with identical zones, since the specified
+// instant, expressed in seconds since January 1, 1970 UTC, and points all
+// of them to a single table of zones. Zones of an original before that
+// instant are replaced by those of the table it is pointed to, which is
+// recorded in the original (see Original.Shared), so passing math.MinInt64
+// only shares tables that are identical. Tables of zones left unused by the
+// originals pointed elsewhere are dropped, including their older versions,
+// and the database file is compacted.
+func DedupZones(since int64) (DedupStats, error) {
+	var stats DedupStats
+	if !dbOpen {
+		return stats, noDB
+	}
+
+	originals, err := getOriginals()
+	if err != nil {
+		return stats, err
+	}
+
+	// group originals by the hash of their zones
+	groups := make(map[[sha256.Size]byte][]*Original)
+	keys := make([][sha256.Size]byte, 0, len(originals))
+	for i := range originals {
+		original := &originals[i]
+		if original.TabVer == 0 {
+			continue
+		}
+		zones, err := getActiveZones(original)
+		if err != nil {
+			// unreliable tables are left alone
+			continue
+		}
+		key := hashZones(zones, since)
+		if groups[key] == nil {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], original)
+	}
+
+	sizeBefore, err := databaseSize()
+	if err != nil {
+		return stats, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	fields := getOriginalCols()
+	query := fmt.Sprintf("UPDATE %q SET %q=?, %q=?, %q=? WHERE %q=?", originalTable, fields[4], fields[5], fields[9], fields[0])
+
+	// the cut-off is NULL, unless history before it may be replaced
+	var sharedSince interface{}
+	if since != math.MinInt64 {
+		sharedSince = since
+	}
+	redirected := make([]Original, 0)
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		stats.Groups++
+
+		// prefer an original that owns its table, so that
+		// tables shared by earlier runs keep being shared
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].SharesZones() != group[j].SharesZones() {
+				return !group[i].SharesZones()
+			}
+			return group[i].Name < group[j].Name
+		})
+		target := group[0]
+		for _, original := range group[1:] {
+			if original.TabName == target.TabName && original.TabVer == target.TabVer {
+				continue
+			}
+			if _, err := tx.Exec(query, target.TabName, target.TabVer, sharedSince, original.ID); err != nil {
+				return stats, err
+			}
+			if !original.SharesZones() {
+				redirected = append(redirected, *original)
+			}
+			original.TabName, original.TabVer = target.TabName, target.TabVer
+			stats.Shared++
+		}
+	}
+
+	// tables of the originals that owned them are dropped,
+	// unless still referenced by any original
+	inUse := make(map[string]bool, len(originals))
+	for _, original := range originals {
+		inUse[fmt.Sprintf("%s%v", original.TabName, original.TabVer)] = true
+	}
+	for _, original := range redirected {
+		for ver := original.TabVer; ver > 0; ver-- {
+			table := fmt.Sprintf("%s%v", original.TabName, ver)
+			if inUse[table] {
+				continue
+			}
+			var count int
+			err := tx.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&count)
+			if err != nil {
+				return stats, err
+			}
+			if count == 0 {
+				continue
+			}
+			if err := tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %q", table)).Scan(&count); err != nil {
+				return stats, err
+			}
+			if _, err := tx.Exec(fmt.Sprintf("DROP TABLE %q", table)); err != nil {
+				return stats, err
+			}
+			stats.Tables++
+			stats.Zones += count
+		}
+	}
+
+	if stats.Shared != 0 || stats.Tables != 0 {
+		if err := bumpGeneration(tx); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, err
+	}
+
+	if stats.Tables == 0 {
+		return stats, nil
+	}
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return stats, err
+	}
+	sizeAfter, err := databaseSize()
+	if err != nil {
+		return stats, err
+	}
+	stats.Bytes = sizeBefore - sizeAfter
+
+	return stats, nil
+}
+
+// hashZones hashes the zones in effect since an instant, as returned
+// by zonesSince.
+func hashZones(zones []Zone, since int64) [sha256.Size]byte {
+	h := sha256.New()
+	for _, zone := range zonesSince(zones, since) {
+		fmt.Fprintf(h, "%s %d %d %d %v %v %v\n", zone.Name, zone.Start, zone.End, zone.Offset, zone.IsDST, zone.IsStd, zone.IsUT)
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// databaseSize returns the size of the database, in bytes.
+func databaseSize() (int64, error) {
+	var pages, pageSize int64
+	if err := db.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
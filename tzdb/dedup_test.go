@@ -0,0 +1,89 @@
+package tzdb
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHashZones(t *testing.T) {
+	lome := []Zone{
+		{Name: "LMT", Offset: 292, Start: math.MinInt64, End: -2429827500},
+		{Name: "GMT", Offset: 0, Start: -2429827499, End: -1}}
+	abidjan := []Zone{
+		{Name: "LMT", Offset: -968, Start: math.MinInt64, End: -1830383033},
+		{Name: "GMT", Offset: 0, Start: -1830383032, End: -1}}
+
+	if hashZones(lome, math.MinInt64) == hashZones(abidjan, math.MinInt64) {
+		t.Errorf("zones differing before 1970 hashed the same since the beginning of time")
+	}
+	if hashZones(lome, 0) != hashZones(abidjan, 0) {
+		t.Errorf("zones identical since 1970 hashed differently")
+	}
+}
+
+func TestSharesZones(t *testing.T) {
+	tests := []struct {
+		original Original
+		shares   bool
+	}{
+		{Original{Name: "Africa/Lome", TabName: "africa_lome"}, false},
+		{Original{Name: "Africa/Lome", TabName: "africa_abidjan"}, true},
+		{Original{Name: "Africa/Lome"}, false},
+	}
+
+	for _, test := range tests {
+		if shares := test.original.SharesZones(); shares != test.shares {
+			t.Errorf("%+v.SharesZones() = %v, want %v", test.original, shares, test.shares)
+		}
+	}
+}
+
+func TestDedupZones(t *testing.T) {
+	openTestDB(t, "Africa/Abidjan", "Africa/Lome", "Europe/Athens")
+
+	// Abidjan and Lome only differ before 1970
+	stats, err := DedupZones(math.MinInt64)
+	if err != nil || stats.Shared != 0 {
+		t.Fatalf("DedupZones since the beginning of time = %+v, %v, want nothing shared", stats, err)
+	}
+
+	stats, err = DedupZones(0)
+	if err != nil || stats.Shared != 1 || stats.Tables == 0 {
+		t.Fatalf("DedupZones since 1970 = %+v, %v, want one original shared", stats, err)
+	}
+	lome, err := GetOriginal("Africa/Lome")
+	if err != nil {
+		t.Fatalf("cannot find Africa/Lome: %s", err)
+	}
+	if !lome.SharesZones() || !lome.Shared || lome.SharedSince != 0 {
+		t.Errorf("Africa/Lome is %+v, want table of Africa/Abidjan shared since 0", lome)
+	}
+	if athens, err := GetOriginal("Europe/Athens"); err != nil || athens.SharesZones() || athens.Shared {
+		t.Errorf("Europe/Athens is %+v, %v, want own table", athens, err)
+	}
+
+	// the history of Abidjan before 1970 is not that of Lome
+	if zone, err := Lookup("Africa/Lome", -1000000000); err == nil {
+		t.Errorf("Lookup of Africa/Lome before 1970 = %+v, want error", zone)
+	}
+	if zone, err := Lookup("Africa/Lome", 1000000000); err != nil || zone.Name != "GMT" {
+		t.Errorf("Lookup of Africa/Lome after 1970 = %+v, %v, want GMT", zone, err)
+	}
+	location, err := LoadLocation("Africa/Lome")
+	if err != nil {
+		t.Fatalf("cannot load Africa/Lome: %s", err)
+	}
+	if abbrev, offset := time.Unix(-1000000000, 0).In(location).Zone(); abbrev != "-00" || offset != 0 {
+		t.Errorf("Africa/Lome before 1970 is %s %+d, want -00", abbrev, offset)
+	}
+
+	// originals updated get their own table again
+	lome.TabVer++
+	if err := UpdateOriginal(lome); err != nil {
+		t.Fatalf("cannot update Africa/Lome: %s", err)
+	}
+	if lome, err = GetOriginal("Africa/Lome"); err != nil || lome.SharesZones() || lome.Shared {
+		t.Errorf("updated Africa/Lome is %+v, %v, want own table", lome, err)
+	}
+}
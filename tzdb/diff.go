@@ -0,0 +1,231 @@
+package tzdb
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Snapshot holds the data of a database, as seen by readers.
+// That is, the original timezones, the replicas and the zones
+// of the most recent reliable table of each original.
+type Snapshot struct {
+	Originals map[string]Original // keyed by name of original
+	Replicas  map[string]string   // name of replica --> name of original
+	Zones     map[string][]Zone   // name of original --> active zones
+}
+
+// Difference describes a single difference between two databases.
+type Difference struct {
+	Timezone string
+	Kind     string // original, replica, default, footer, version or zones
+	A, B     string // description of what each database holds
+}
+
+// LoadSnapshot reads all the data of the open database.
+// Originals without a reliable table of zones get no entry
+// in the map of zones, just like GetZones would fail for them.
+func LoadSnapshot() (*Snapshot, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	originals, err := getOriginals()
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, err := getReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		Originals: make(map[string]Original, len(originals)),
+		Replicas:  make(map[string]string, len(replicas)),
+		Zones:     make(map[string][]Zone, len(originals))}
+
+	names := make(map[int64]string, len(originals))
+	for i := range originals {
+		snap.Originals[originals[i].Name] = originals[i]
+		names[originals[i].ID] = originals[i].Name
+
+		zones, err := getActiveZones(&originals[i])
+		if err != nil {
+			// no reliable table of zones
+			continue
+		}
+		snap.Zones[originals[i].Name] = zones
+	}
+
+	for _, replica := range replicas {
+		// a replica may point to a missing original,
+		// in which case the name remains empty
+		snap.Replicas[replica.Name] = names[replica.ProtoID]
+	}
+
+	return snap, nil
+}
+
+// Diff compares the databases stored in the specified files.
+// Each file is opened in read-only mode, loaded and closed in turn,
+// so any previously opened database must be re-opened afterwards.
+// Table names, table versions and IDs are not taken into account.
+func Diff(fileA, fileB string) ([]Difference, error) {
+	snapA, err := loadSnapshotFile(fileA)
+	if err != nil {
+		return nil, err
+	}
+
+	snapB, err := loadSnapshotFile(fileB)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareSnapshots(snapA, snapB), nil
+}
+
+func loadSnapshotFile(filename string) (*Snapshot, error) {
+	if err := OpenRO(filename); err != nil {
+		return nil, err
+	}
+	defer Close()
+
+	snap, err := LoadSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("tzdb: cannot load %q: %s", filename, err)
+	}
+
+	return snap, nil
+}
+
+// CompareSnapshots reports all differences between two snapshots.
+// Differences are sorted by name of timezone.
+func CompareSnapshots(a, b *Snapshot) []Difference {
+	diffs := make([]Difference, 0, 10)
+
+	for _, name := range unionKeys(originalNames(a), originalNames(b)) {
+		orgA, inA := a.Originals[name]
+		orgB, inB := b.Originals[name]
+		if !inA || !inB {
+			diffs = append(diffs, Difference{Timezone: name, Kind: "original",
+				A: presence(inA), B: presence(inB)})
+			continue
+		}
+
+		if orgA.DZone != orgB.DZone || orgA.DOffset != orgB.DOffset {
+			diffs = append(diffs, Difference{Timezone: name, Kind: "default",
+				A: fmt.Sprintf("%s %+d", orgA.DZone, orgA.DOffset),
+				B: fmt.Sprintf("%s %+d", orgB.DZone, orgB.DOffset)})
+		}
+
+		if orgA.Footer != orgB.Footer {
+			diffs = append(diffs, Difference{Timezone: name, Kind: "footer",
+				A: orgA.Footer, B: orgB.Footer})
+		}
+
+		if orgA.TZDVer != orgB.TZDVer {
+			diffs = append(diffs, Difference{Timezone: name, Kind: "version",
+				A: orgA.TZDVer, B: orgB.TZDVer})
+		}
+
+		if diff, differ := compareZones(a.Zones[name], b.Zones[name]); differ {
+			diff.Timezone = name
+			diffs = append(diffs, diff)
+		}
+	}
+
+	for _, name := range unionKeys(replicaNames(a), replicaNames(b)) {
+		orgA, inA := a.Replicas[name]
+		orgB, inB := b.Replicas[name]
+		if inA && inB && orgA == orgB {
+			continue
+		}
+		diff := Difference{Timezone: name, Kind: "replica", A: presence(inA), B: presence(inB)}
+		if inA {
+			diff.A = "link to " + orgA
+		}
+		if inB {
+			diff.B = "link to " + orgB
+		}
+		diffs = append(diffs, diff)
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Timezone < diffs[j].Timezone
+	})
+
+	return diffs
+}
+
+// compareZones reports the first difference between two sets of zones.
+// Zone IDs are ignored, since they depend on how the table was populated.
+func compareZones(a, b []Zone) (diff Difference, differ bool) {
+	diff.Kind = "zones"
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if !sameZone(a[i], b[i]) {
+			diff.A = fmt.Sprintf("zone #%d: %s", i, describeZone(a[i]))
+			diff.B = fmt.Sprintf("zone #%d: %s", i, describeZone(b[i]))
+			return diff, true
+		}
+	}
+
+	if len(a) != len(b) {
+		diff.A = fmt.Sprintf("%d zones", len(a))
+		diff.B = fmt.Sprintf("%d zones", len(b))
+		return diff, true
+	}
+
+	return diff, false
+}
+
+func sameZone(a, b Zone) bool {
+	return a.Name == b.Name && a.Start == b.Start && a.End == b.End &&
+		a.Offset == b.Offset && a.IsDST == b.IsDST
+}
+
+func describeZone(z Zone) string {
+	return fmt.Sprintf("%s %+d dst=%v [%d, %d]", z.Name, z.Offset, z.IsDST, z.Start, z.End)
+}
+
+func presence(present bool) string {
+	if present {
+		return "present"
+	}
+	return "missing"
+}
+
+func originalNames(s *Snapshot) []string {
+	names := make([]string, 0, len(s.Originals))
+	for name := range s.Originals {
+		names = append(names, name)
+	}
+	return names
+}
+
+func replicaNames(s *Snapshot) []string {
+	names := make([]string, 0, len(s.Replicas))
+	for name := range s.Replicas {
+		names = append(names, name)
+	}
+	return names
+}
+
+// unionKeys merges two lists of names into a sorted list without duplicates.
+func unionKeys(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	union := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				union = append(union, name)
+			}
+		}
+	}
+	sort.Strings(union)
+	return union
+}
@@ -0,0 +1,63 @@
+package tzdb
+
+import (
+	"testing"
+)
+
+func testSnapshot() *Snapshot {
+	return &Snapshot{
+		Originals: map[string]Original{
+			"Europe/Athens": {ID: 1, Name: "Europe/Athens", DZone: "EET", DOffset: 7200, TabName: "europe_athens", TabVer: 1, TZDVer: "2020a"},
+			"Etc/UTC":       {ID: 2, Name: "Etc/UTC", DZone: "UTC", DOffset: 0, TabName: "etc_utc", TabVer: 0, TZDVer: "2020a"}},
+		Replicas: map[string]string{
+			"Europe/Athens": "Europe/Athens",
+			"Etc/UTC":       "Etc/UTC",
+			"Zulu":          "Etc/UTC"},
+		Zones: map[string][]Zone{
+			"Europe/Athens": {
+				{ID: 1, Name: "EET", Start: 100, End: 199, Offset: 7200, IsDST: false},
+				{ID: 2, Name: "EEST", Start: 200, End: -1, Offset: 10800, IsDST: true}}}}
+}
+
+func TestCompareSnapshotsEqual(t *testing.T) {
+	a, b := testSnapshot(), testSnapshot()
+
+	// table versions and IDs should be ignored
+	org := b.Originals["Europe/Athens"]
+	org.ID, org.TabVer = 7, 3
+	b.Originals["Europe/Athens"] = org
+	b.Zones["Europe/Athens"][0].ID = 42
+
+	if diffs := CompareSnapshots(a, b); len(diffs) != 0 {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+}
+
+func TestCompareSnapshotsDiffer(t *testing.T) {
+	a, b := testSnapshot(), testSnapshot()
+
+	delete(b.Replicas, "Zulu")
+	b.Replicas["UTC"] = "Etc/UTC"
+	b.Zones["Europe/Athens"][1].Offset = 14400
+	org := b.Originals["Etc/UTC"]
+	org.DZone = "GMT"
+	b.Originals["Etc/UTC"] = org
+
+	want := []Difference{
+		{Timezone: "Etc/UTC", Kind: "default", A: "UTC +0", B: "GMT +0"},
+		{Timezone: "Europe/Athens", Kind: "zones",
+			A: "zone #1: EEST +10800 dst=true [200, -1]",
+			B: "zone #1: EEST +14400 dst=true [200, -1]"},
+		{Timezone: "UTC", Kind: "replica", A: "missing", B: "link to Etc/UTC"},
+		{Timezone: "Zulu", Kind: "replica", A: "link to Etc/UTC", B: "missing"}}
+
+	diffs := CompareSnapshots(a, b)
+	if len(diffs) != len(want) {
+		t.Fatalf("expected %d differences, got %d: %v", len(want), len(diffs), diffs)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("difference #%d is %v, want %v", i, diffs[i], want[i])
+		}
+	}
+}
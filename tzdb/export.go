@@ -0,0 +1,262 @@
+package tzdb
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ExportFilter selects the data to export.
+type ExportFilter struct {
+	Pattern string // pattern of timezone names, as in path.Match; empty for all
+	From    int64  // zones that end before From are left out
+	To      int64  // zones that start after To are left out
+}
+
+// AllData is a filter that selects all data.
+var AllData = ExportFilter{From: math.MinInt64, To: math.MaxInt64}
+
+// Export holds the data of a database, as seen by readers,
+// in a form suitable for exchanging them with other programs.
+type Export struct {
+	TZDataVersion string             `json:"tzdata_version"`
+	Timezones     []ExportedTimezone `json:"timezones"`
+}
+
+// ExportedTimezone is an original timezone, along with its replicas
+// and the zones of its active table. Originals without a table of
+// zones have no zones, but only a default zone and offset. Zones of
+// clipped originals are missing before ClippedSince.
+type ExportedTimezone struct {
+	Name          string         `json:"name"`
+	DefaultZone   string         `json:"default_zone"`
+	DefaultOffset int64          `json:"default_offset"`
+	TZDataVersion string         `json:"tzdata_version"`
+	Footer        string         `json:"footer"`
+	ClippedSince  *int64         `json:"clipped_since,omitempty"` // nil unless clipped
+	Replicas      []string       `json:"replicas"`
+	Zones         []ExportedZone `json:"zones"`
+}
+
+// ExportedZone is a zone of an exported timezone.
+type ExportedZone struct {
+	Abbrev string `json:"abbrev"`
+	Offset int64  `json:"offset"`
+	IsDST  bool   `json:"is_dst"`
+	IsStd  bool   `json:"is_std"` // as in Zone
+	IsUT   bool   `json:"is_ut"`  // as in Zone
+	Start  int64  `json:"start"`
+	End    int64  `json:"end"` // -1 if in effect until the end of time
+}
+
+// Columns of each table, as exported in flat formats.
+var (
+	ExportOriginalCols = []string{"name", "default_zone", "default_offset", "tzdata_version", "footer", "clipped_since"}
+	ExportReplicaCols  = []string{"name", "original"}
+	ExportZoneCols     = []string{"timezone", "abbrev", "offset", "is_dst", "is_std", "is_ut", "start", "end"}
+)
+
+// ExportData collects the data of the open database that pass the filter.
+// A timezone passes if its name or the name of any of its replicas matches
+// the pattern of the filter, but only the matching replicas are exported.
+// Timezones are sorted by name.
+func ExportData(filter ExportFilter) (*Export, error) {
+	if filter.Pattern != "" {
+		if _, err := path.Match(filter.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("tzdb: invalid pattern %q: %s", filter.Pattern, err)
+		}
+	}
+	matches := func(name string) bool {
+		matched, _ := path.Match(filter.Pattern, name)
+		return filter.Pattern == "" || matched
+	}
+
+	snap, err := LoadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := GetTZDataVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make(map[string][]string, len(snap.Originals))
+	for replica, original := range snap.Replicas {
+		if original != "" && matches(replica) {
+			replicas[original] = append(replicas[original], replica)
+		}
+	}
+
+	export := &Export{TZDataVersion: version, Timezones: make([]ExportedTimezone, 0, len(snap.Originals))}
+	for name, original := range snap.Originals {
+		if len(replicas[name]) == 0 && !matches(name) {
+			continue
+		}
+		sort.Strings(replicas[name])
+
+		timezone := ExportedTimezone{
+			Name:          name,
+			DefaultZone:   original.DZone,
+			DefaultOffset: original.DOffset,
+			TZDataVersion: original.TZDVer,
+			Footer:        original.Footer,
+			Replicas:      append(make([]string, 0), replicas[name]...),
+			Zones:         make([]ExportedZone, 0)}
+		if original.Clipped {
+			since := original.Since
+			timezone.ClippedSince = &since
+		}
+		for _, zone := range ZonesBetween(snap.Zones[name], filter.From, filter.To) {
+			timezone.Zones = append(timezone.Zones, ExportedZone{Abbrev: zone.Name,
+				Offset: zone.Offset, IsDST: zone.IsDST, IsStd: zone.IsStd, IsUT: zone.IsUT, Start: zone.Start, End: zone.End})
+		}
+		export.Timezones = append(export.Timezones, timezone)
+	}
+
+	sort.Slice(export.Timezones, func(i, j int) bool {
+		return export.Timezones[i].Name < export.Timezones[j].Name
+	})
+
+	return export, nil
+}
+
+// rows returns the exported data as rows of each table, in the order of
+// the respective columns. Every value is formatted as a string, except
+// for numbers and booleans, which are kept as they are, and missing
+// values, which are nil.
+func (e *Export) rows() (originals, replicas, zones [][]interface{}) {
+	for _, tz := range e.Timezones {
+		var since interface{}
+		if tz.ClippedSince != nil {
+			since = *tz.ClippedSince
+		}
+		originals = append(originals, []interface{}{tz.Name, tz.DefaultZone, tz.DefaultOffset, tz.TZDataVersion, tz.Footer, since})
+		for _, replica := range tz.Replicas {
+			replicas = append(replicas, []interface{}{replica, tz.Name})
+		}
+		for _, zone := range tz.Zones {
+			zones = append(zones, []interface{}{tz.Name, zone.Abbrev, zone.Offset, zone.IsDST, zone.IsStd, zone.IsUT, zone.Start, zone.End})
+		}
+	}
+	return originals, replicas, zones
+}
+
+// WriteJSON writes the exported data as a single JSON document.
+// Nested documents hold a list of timezones, each with its replicas
+// and zones. Flat documents hold a list of rows for each table.
+func (e *Export) WriteJSON(w io.Writer, flat bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if !flat {
+		return encoder.Encode(e)
+	}
+
+	originals, replicas, zones := e.rows()
+	return encoder.Encode(map[string]interface{}{
+		"tzdata_version": e.TZDataVersion,
+		"originals":      rowObjects(ExportOriginalCols, originals),
+		"replicas":       rowObjects(ExportReplicaCols, replicas),
+		"zones":          rowObjects(ExportZoneCols, zones)})
+}
+
+// WriteNDJSON writes the exported data as JSON lines, one per row of each
+// table, with a "table" field that tells which table the row belongs to.
+func (e *Export) WriteNDJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	originals, replicas, zones := e.rows()
+	for _, table := range []struct {
+		name    string
+		columns []string
+		rows    [][]interface{}
+	}{
+		{"original", ExportOriginalCols, originals},
+		{"replica", ExportReplicaCols, replicas},
+		{"zone", ExportZoneCols, zones},
+	} {
+		for _, object := range rowObjects(table.columns, table.rows) {
+			object["table"] = table.name
+			if err := encoder.Encode(object); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes the exported data in CSV format, in one file per table
+// (originals.csv, replicas.csv and zones.csv) in the specified directory.
+// The first line of each file holds the names of the columns.
+func (e *Export) WriteCSV(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	originals, replicas, zones := e.rows()
+	for _, table := range []struct {
+		file    string
+		columns []string
+		rows    [][]interface{}
+	}{
+		{"originals.csv", ExportOriginalCols, originals},
+		{"replicas.csv", ExportReplicaCols, replicas},
+		{"zones.csv", ExportZoneCols, zones},
+	} {
+		if err := writeCSVFile(filepath.Join(dir, table.file), table.columns, table.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSVFile(filename string, columns []string, rows [][]interface{}) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(file)
+	w.Write(columns)
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, value := range row {
+			switch v := value.(type) {
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case bool:
+				record[i] = strconv.FormatBool(v)
+			case nil:
+				record[i] = ""
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		w.Write(record)
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func rowObjects(columns []string, rows [][]interface{}) []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		object := make(map[string]interface{}, len(columns)+1)
+		for i, column := range columns {
+			object[column] = row[i]
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
@@ -0,0 +1,100 @@
+package tzdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testExport() *Export {
+	since := int64(-1178161200)
+	return &Export{TZDataVersion: "2020d", Timezones: []ExportedTimezone{
+		{Name: "Etc/UTC", DefaultZone: "UTC", TZDataVersion: "2020d", Replicas: []string{"UTC", "Zulu"}, Zones: []ExportedZone{}},
+		{Name: "Europe/Athens", DefaultZone: "EET", DefaultOffset: 7200, TZDataVersion: "2020d", Footer: "EET-2EEST,M3.5.0/3,M10.5.0/4",
+			ClippedSince: &since, Replicas: []string{"Europe/Athens"}, Zones: []ExportedZone{
+				{Abbrev: "EET", Offset: 7200, IsStd: true, IsUT: true, Start: -1178161200, End: -1}}},
+	}}
+}
+
+func TestExportWriters(t *testing.T) {
+	export := testExport()
+
+	nested := &bytes.Buffer{}
+	if err := export.WriteJSON(nested, false); err != nil {
+		t.Fatalf("cannot write nested JSON: %s", err)
+	}
+	var decoded Export
+	if err := json.Unmarshal(nested.Bytes(), &decoded); err != nil || len(decoded.Timezones) != 2 {
+		t.Errorf("nested JSON does not decode back: %v", err)
+	}
+
+	// 2 originals, 3 replicas and 1 zone
+	lines := &bytes.Buffer{}
+	if err := export.WriteNDJSON(lines); err != nil {
+		t.Fatalf("cannot write NDJSON: %s", err)
+	}
+	if count := strings.Count(lines.String(), "\n"); count != 6 {
+		t.Errorf("NDJSON has %d lines, want 6", count)
+	}
+
+	dir, err := ioutil.TempDir("", "tzdb-export")
+	if err != nil {
+		t.Fatalf("cannot create directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := export.WriteCSV(dir); err != nil {
+		t.Fatalf("cannot write CSV: %s", err)
+	}
+	for file, want := range map[string]int{"originals.csv": 3, "replicas.csv": 4, "zones.csv": 2} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if count := strings.Count(string(data), "\n"); err != nil || count != want {
+			t.Errorf("%s has %d lines, want %d (header included)", file, count, want)
+		}
+	}
+}
+
+func TestReadExport(t *testing.T) {
+	export := testExport()
+
+	for _, write := range []func(b *bytes.Buffer) error{
+		func(b *bytes.Buffer) error { return export.WriteJSON(b, false) },
+		func(b *bytes.Buffer) error { return export.WriteJSON(b, true) },
+		func(b *bytes.Buffer) error { return export.WriteNDJSON(b) },
+	} {
+		buf := &bytes.Buffer{}
+		if err := write(buf); err != nil {
+			t.Fatalf("cannot write export: %s", err)
+		}
+		read, err := ReadExport(buf)
+		if err != nil {
+			t.Errorf("cannot read export: %s", err)
+			continue
+		}
+		if !reflect.DeepEqual(read, export) {
+			t.Errorf("read %+v, want %+v", read, export)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "tzdb-export")
+	if err != nil {
+		t.Fatalf("cannot create directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := export.WriteCSV(dir); err != nil {
+		t.Fatalf("cannot write CSV: %s", err)
+	}
+	if read, err := ReadExportCSV(dir); err != nil || !reflect.DeepEqual(read, export) {
+		t.Errorf("read %+v, %v, want %+v", read, err, export)
+	}
+
+	if _, err := ReadExport(strings.NewReader(`{"something": "else"}`)); err == nil {
+		t.Errorf("unknown format should be rejected")
+	}
+}
@@ -0,0 +1,51 @@
+package tzdb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetGeneration retrieves the generation of the data of the open database.
+// It changes with every change made through this package, even if the
+// version of TZ-data stays the same, as with overrides or an import, so that
+// data derived from the database can be kept for as long as it does not
+// change. The generation is the time of the last change in nanoseconds, or
+// the one after the previous generation, so that it also differs between
+// databases. Databases never changed since it was introduced are of
+// generation 0.
+func GetGeneration() (int64, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return 0, noDB
+	}
+
+	return getGeneration(db)
+}
+
+func getGeneration(conn *sql.DB) (generation int64, err error) {
+	var name string
+	query := fmt.Sprintf("SELECT name FROM sqlite_master WHERE type='table' AND name='%s'", generationTable)
+	if err := conn.QueryRow(query).Scan(&name); err != nil {
+		return 0, nil
+	}
+
+	fields := getGenerationCols()
+	query = fmt.Sprintf("SELECT IFNULL(MAX(%s), 0) FROM %s", fields[1], generationTable)
+	err = conn.QueryRow(query).Scan(&generation)
+	return generation, err
+}
+
+// bumpGeneration starts a new generation of data, on conn.
+// Setters call it along with their changes, within the same
+// transaction if they use one.
+func bumpGeneration(conn dbConn) error {
+	fields := getGenerationCols()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES(0, ?) ON CONFLICT(%s) DO UPDATE SET %s=MAX(%s+1, excluded.%s)",
+		generationTable, fields[0], fields[1], fields[0], fields[1], fields[1], fields[1])
+
+	_, err := conn.Exec(query, time.Now().UnixNano())
+	return err
+}
@@ -1,6 +1,7 @@
 package tzdb
 
 import (
+	"database/sql"
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"strconv"
@@ -12,10 +13,35 @@ import (
 // The table of original timezones contains the name of the
 // table with the corresponding zones.
 func GetZones(timezone string) (zones []Zone, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
 	if !dbOpen {
 		return nil, noDB
 	}
 
+	original, err := getOriginal(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return getActiveZones(original)
+}
+
+// GetOriginal retrieves the original timezone for specified timezone.
+// The specified timezone is treated as a replica (link), as in GetZones.
+func GetOriginal(timezone string) (*Original, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	return getOriginal(timezone)
+}
+
+func getOriginal(timezone string) (*Original, error) {
 	// get id of original timezone from replicas' table
 	protoID, err := getReplicaOriginal(timezone)
 	if err != nil {
@@ -30,6 +56,12 @@ func GetZones(timezone string) (zones []Zone, err error) {
 		return nil, err
 	}
 
+	return original, nil
+}
+
+// getActiveZones retrieves the zones of the most recent
+// reliable table of zones of the specified original.
+func getActiveZones(original *Original) (zones []Zone, err error) {
 	// check all available sub-tables with zones
 	// start from the most recent -- the last one
 	// stop when a reliable table is found
@@ -52,7 +84,113 @@ func GetZones(timezone string) (zones []Zone, err error) {
 	return zones, nil
 }
 
+// GetOriginals retrieves all entries of the table of original timezones.
+func GetOriginals() (originals []Original, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	return getOriginals()
+}
+
+func getOriginals() (originals []Original, err error) {
+	columns := getOriginalCols()
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s", originalTable, columns[0])
+	return queryOriginals(query)
+}
+
+// queryOriginals runs a query on the table of original timezones, with
+// placeholders for the specified arguments. Databases created by older
+// versions lack some of the columns, which are then left empty, since
+// read-only ones cannot be updated.
+func queryOriginals(query string, args ...interface{}) (originals []Original, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	originals = make([]Original, 0, 500)
+	for rows.Next() {
+		var o Original
+		var since, sharedSince sql.NullInt64
+		fields := []interface{}{&o.ID, &o.Name, &o.DZone, &o.DOffset, &o.TabName, &o.TabVer, &o.TZDVer, &o.Footer, &since, &sharedSince}
+		if len(stored) < len(fields) {
+			fields = fields[:len(stored)]
+		}
+		err = rows.Scan(fields...)
+		if err != nil {
+			return nil, err
+		}
+		o.Clipped, o.Since = since.Valid, since.Int64
+		o.Shared, o.SharedSince = sharedSince.Valid, sharedSince.Int64
+		originals = append(originals, o)
+	}
+
+	return originals, rows.Err()
+}
+
+// queryOriginal runs a query for a single original timezone.
+func queryOriginal(query string, args ...interface{}) (*Original, error) {
+	originals, err := queryOriginals(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(originals) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &originals[0], nil
+}
+
+// GetReplicas retrieves all entries of the table of replicas.
+func GetReplicas() (replicas []Replica, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	return getReplicas()
+}
+
+func getReplicas() (replicas []Replica, err error) {
+	columns := getReplicaCols()
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY %s", replicaTable, columns[0])
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var name string
+	var id, protoID int64
+
+	replicas = make([]Replica, 0, 600)
+	for rows.Next() {
+		err = rows.Scan(&id, &name, &protoID)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, Replica{ID: id, Name: name, ProtoID: protoID})
+	}
+
+	return replicas, rows.Err()
+}
+
 func GetOriginalCount() (count int, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
 	if !dbOpen {
 		return 0, noDB
 	}
@@ -62,6 +200,9 @@ func GetOriginalCount() (count int, err error) {
 }
 
 func GetReplicaCount() (count int, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
 	if !dbOpen {
 		return 0, noDB
 	}
@@ -71,6 +212,9 @@ func GetReplicaCount() (count int, err error) {
 }
 
 func GetZoneCount(table string) (count int, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
 	if !dbOpen {
 		return 0, noDB
 	}
@@ -80,6 +224,9 @@ func GetZoneCount(table string) (count int, err error) {
 }
 
 func GetZoneTableMeta(originalID int) (tableVer int, storedZones int, version string, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
 	if !dbOpen {
 		return 0, 0, "", noDB
 	}
@@ -99,6 +246,57 @@ func GetZoneTableMeta(originalID int) (tableVer int, storedZones int, version st
 	return int(original.TabVer), storedZones, original.TZDVer, nil
 }
 
+// ZoneTableHasIndicators reports whether the active table of zones of an
+// original stores the standard time and UT indicators of its zones, which
+// tables written by earlier versions of the generator lack.
+func ZoneTableHasIndicators(originalID int) (bool, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return false, noDB
+	}
+
+	original, err := getOriginalByID(originalID)
+	if err != nil {
+		return false, err
+	}
+
+	zoneTable := fmt.Sprintf("%s%v", original.TabName, original.TabVer)
+	if !tableExists(zoneTable) {
+		return false, fmt.Errorf("tzdb: table of zones %s does not exist", zoneTable)
+	}
+
+	columns := getZoneCols()
+	return columnExists(zoneTable, columns[6]) && columnExists(zoneTable, columns[7]), nil
+}
+
+// GetTZDataVersion retrieves the most recent version of
+// TZ-data used to update any of the original timezones.
+func GetTZDataVersion() (version string, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return "", noDB
+	}
+
+	return getTZDataVersion(db)
+}
+
+// getTZDataVersion queries the specified connection, which
+// is not necessarily the one currently in use by tzdb.
+func getTZDataVersion(conn *sql.DB) (version string, err error) {
+	columns := getOriginalCols()
+	query := fmt.Sprintf("SELECT IFNULL(MAX(%s), '') FROM %s", columns[6], originalTable)
+	err = conn.QueryRow(query).Scan(&version)
+	if err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
 func getCount(column, table string) (count int, err error) {
 	query := fmt.Sprintf("SELECT COUNT(%s) FROM %s", column, table)
 	stmt, err := db.Prepare(query)
@@ -132,32 +330,19 @@ func getReplicaOriginal(replicaTZ string) (originalID int, err error) {
 
 // getOriginalByID retrieves data for an origial TZ with specified ID.
 func getOriginalByID(originalID int) (*Original, error) {
-	var name, zone, ztname, tzdver string
-	var id, ztver, offset int64
-
 	columns := getOriginalCols()
 	query := fmt.Sprintf("SELECT * FROM %s WHERE %s=%v", originalTable, columns[0], originalID)
-	err := db.QueryRow(query).Scan(&id, &name, &zone, &offset, &ztname, &ztver, &tzdver)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Original{ID: id, Name: name, DZone: zone, DOffset: offset, TabName: ztname, TabVer: ztver, TZDVer: tzdver}, nil
+	return queryOriginal(query)
 }
 
 // GetOriginalByName retrieves ID for a named origial TZ.
 func GetOriginalByName(originalTZ string) (*Original, error) {
-	var name, dzone, ztname, tzdatver string
-	var id, tzver, doffset int64
+	dbLock.RLock()
+	defer dbLock.RUnlock()
 
 	columns := getOriginalCols()
 	query := fmt.Sprintf("SELECT * FROM %s WHERE %s=%q", originalTable, columns[1], originalTZ)
-	err := db.QueryRow(query).Scan(&id, &name, &dzone, &doffset, &ztname, &tzver, &tzdatver)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Original{ID: id, Name: name, DZone: dzone, DOffset: doffset, TabName: ztname, TabVer: tzver, TZDVer: tzdatver}, nil
+	return queryOriginal(query)
 }
 
 // getZones retrieves all zones from specified table.
@@ -173,18 +358,24 @@ func getZones(zoneTable string) (zones []Zone, err error) {
 		return nil, err
 	}
 
-	var id, start, end, offset int64
-	var name string
-	var isDST bool
+	// tables written by older versions lack the indicators
+	stored, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
 
 	zones = make([]Zone, 0, 5)
 	for rows.Next() {
-		err = rows.Scan(&id, &name, &start, &end, &offset, &isDST)
+		var z Zone
+		fields := []interface{}{&z.ID, &z.Name, &z.Start, &z.End, &z.Offset, &z.IsDST, &z.IsStd, &z.IsUT}
+		if len(stored) < len(fields) {
+			fields = fields[:len(stored)]
+		}
+		err = rows.Scan(fields...)
 		if err != nil {
 			return nil, err
 		}
-		newZone := Zone{ID: id, Name: name, Start: start, End: end, Offset: offset, IsDST: isDST}
-		zones = append(zones, newZone)
+		zones = append(zones, z)
 	}
 
 	return zones, nil
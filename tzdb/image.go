@@ -0,0 +1,280 @@
+package tzdb
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// An image holds a whole database in a single blob, which can be embedded
+// in a Go binary. It is a gzip-compressed SQL script, which recreates the
+// database when run. The sqlite driver cannot open the bytes of a database
+// file directly, but it can run the script on a database kept in memory.
+// Each line of the script is a single statement.
+const imageHeader = "-- tzdb image 1\n"
+
+var (
+	// Connections to an in-memory database share its data,
+	// which are lost as soon as the last connection closes.
+	// So, one connection is held for as long as db is open.
+	imageConn  *sql.Conn
+	imageCount int64
+)
+
+// WriteImage writes an image of the open database, to be opened with
+// OpenImage or OpenFS. Table names, IDs and versions are all preserved.
+func WriteImage(w io.Writer) error {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return noDB
+	}
+
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	bw.WriteString(imageHeader)
+	bw.WriteString("BEGIN;\n")
+
+	tables, err := getSchemas("table")
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		bw.WriteString(table.schema + ";\n")
+		if err := writeTableRows(bw, table.name); err != nil {
+			return fmt.Errorf("tzdb: cannot dump table %q: %s", table.name, err)
+		}
+	}
+
+	// indexes are created once rows are added, which is faster
+	indexes, err := getSchemas("index")
+	if err != nil {
+		return err
+	}
+	for _, index := range indexes {
+		bw.WriteString(index.schema + ";\n")
+	}
+
+	// sequences are restored last, since inserting rows updates them
+	bw.WriteString("DELETE FROM sqlite_sequence;\n")
+	if err := writeTableRows(bw, "sqlite_sequence"); err != nil {
+		return fmt.Errorf("tzdb: cannot dump sequences: %s", err)
+	}
+
+	bw.WriteString("COMMIT;\n")
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+type tableSchema struct {
+	name   string
+	schema string
+}
+
+// getSchemas retrieves the statements that create each table or index,
+// except for the internal ones, which sqlite creates on its own.
+func getSchemas(kind string) ([]tableSchema, error) {
+	query := "SELECT name, sql FROM sqlite_master WHERE type=? AND name NOT LIKE 'sqlite_%' AND sql IS NOT NULL ORDER BY rowid"
+	rows, err := db.Query(query, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]tableSchema, 0, 500)
+	for rows.Next() {
+		var table tableSchema
+		if err := rows.Scan(&table.name, &table.schema); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+// rowsPerInsert is the amount of rows added by each INSERT statement
+// of an image. Statements are much slower to run than rows to add.
+const rowsPerInsert = 500
+
+// writeTableRows writes INSERT statements for all rows of a table.
+func writeTableRows(w *bufio.Writer, table string) error {
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %q", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	fields := make([]interface{}, len(columns))
+	for i := range values {
+		fields[i] = &values[i]
+	}
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(fields...); err != nil {
+			return err
+		}
+
+		if count%rowsPerInsert == 0 {
+			if count > 0 {
+				w.WriteString(";\n")
+			}
+			fmt.Fprintf(w, "INSERT INTO %q VALUES", table)
+		} else {
+			w.WriteByte(',')
+		}
+		count++
+
+		w.WriteByte('(')
+		for i, value := range values {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(sqlLiteral(value))
+		}
+		w.WriteByte(')')
+	}
+	if count > 0 {
+		w.WriteString(";\n")
+	}
+
+	return rows.Err()
+}
+
+// sqlLiteral formats a value, as retrieved by the sqlite driver, in SQL.
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return fmt.Sprintf("X'%X'", v)
+	default:
+		// keep each statement in a single line
+		r := strings.NewReplacer("'", "''", "\n", "'||char(10)||'", "\r", "'||char(13)||'")
+		return "'" + r.Replace(fmt.Sprint(v)) + "'"
+	}
+}
+
+// OpenImage opens the database held in an image, as written by WriteImage.
+// The database is kept in memory and is read-only, as if opened with OpenRO.
+func OpenImage(image []byte) error {
+	zr, err := gzip.NewReader(bytes.NewReader(image))
+	if err != nil {
+		return fmt.Errorf("tzdb: invalid image: %s", err)
+	}
+	script, err := io.ReadAll(zr)
+	if err != nil {
+		return fmt.Errorf("tzdb: invalid image: %s", err)
+	}
+	if !bytes.HasPrefix(script, []byte(imageHeader)) {
+		return fmt.Errorf("tzdb: invalid image: unknown format")
+	}
+
+	name := fmt.Sprintf("tzdb-image-%d", atomic.AddInt64(&imageCount, 1))
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared&_query_only=1", name)
+	dbObj, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+
+	conn, err := dbObj.Conn(context.Background())
+	if err != nil {
+		dbObj.Close()
+		return err
+	}
+
+	// only the connection that loads the image may write
+	ctx := context.Background()
+	_, err = conn.ExecContext(ctx, "PRAGMA query_only=0;")
+	if err == nil {
+		err = runScript(ctx, conn, script)
+	}
+	if err == nil {
+		_, err = conn.ExecContext(ctx, "PRAGMA query_only=1;")
+	}
+	if err != nil {
+		conn.Close()
+		dbObj.Close()
+		return fmt.Errorf("tzdb: cannot load image: %s", err)
+	}
+
+	// a database opened before, watched or not, is replaced
+	stopWatching()
+
+	dbLock.Lock()
+	defer dbLock.Unlock()
+
+	if dbOpen {
+		if imageConn != nil {
+			imageConn.Close()
+		}
+		db.Close()
+	}
+	dbOpen = true
+	db = dbObj
+	imageConn = conn
+
+	return nil
+}
+
+// runScript runs the statements of an image one by one, since the
+// driver copies the rest of a script for each statement it runs.
+func runScript(ctx context.Context, conn *sql.Conn, script []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(script))
+	scanner.Buffer(make([]byte, 64*1024), len(script))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, line); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// OpenFS opens the image stored in the named file of fsys,
+// which is typically an embed.FS, as in:
+//
+//	//go:embed tsdb.img
+//	var images embed.FS
+//	...
+//	tzdb.OpenFS(images, "tsdb.img")
+func OpenFS(fsys fs.FS, name string) error {
+	image, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	return OpenImage(image)
+}
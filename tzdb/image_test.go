@@ -0,0 +1,53 @@
+package tzdb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestImageRoundTrip(t *testing.T) {
+	// restore the database used by the rest of the tests
+	defer Open("../tsdb.sqlite")
+
+	snapFile, err := LoadSnapshot()
+	if err != nil {
+		t.Fatalf("cannot load snapshot of database: %s", err)
+	}
+
+	image := &bytes.Buffer{}
+	if err := WriteImage(image); err != nil {
+		t.Fatalf("cannot write image: %s", err)
+	}
+	Close()
+
+	if err := OpenImage(image.Bytes()); err != nil {
+		t.Fatalf("cannot open image: %s", err)
+	}
+	defer Close()
+
+	snapImage, err := LoadSnapshot()
+	if err != nil {
+		t.Fatalf("cannot load snapshot of image: %s", err)
+	}
+
+	if diffs := CompareSnapshots(snapFile, snapImage); len(diffs) != 0 {
+		t.Errorf("image differs from database: %v", diffs)
+	}
+
+	if _, err := db.Exec("DELETE FROM " + replicaTable); err == nil {
+		t.Errorf("image should be read-only")
+	}
+
+	if err := OpenImage([]byte("not an image")); err == nil {
+		t.Errorf("invalid image should be rejected")
+	}
+
+	// opening an image over another one closes the first
+	previous := db
+	if err := OpenImage(image.Bytes()); err != nil {
+		t.Fatalf("cannot open image again: %s", err)
+	}
+	if err := previous.Ping(); err == nil {
+		t.Errorf("image opened before was not closed")
+	}
+}
@@ -0,0 +1,352 @@
+package tzdb
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Kinds of problems reported by CheckImport, besides ProblemZones.
+const (
+	ProblemUnknown   = "unknown-original"
+	ProblemDuplicate = "duplicate-original"
+	ProblemTruncated = "truncated-zones"
+)
+
+// exportRow is a row of any table, as written by WriteJSON (flat) and
+// WriteNDJSON. Fields that do not belong to its table are left empty.
+type exportRow struct {
+	Table         string `json:"table"`
+	Name          string `json:"name"`
+	DefaultZone   string `json:"default_zone"`
+	DefaultOffset int64  `json:"default_offset"`
+	TZDataVersion string `json:"tzdata_version"`
+	Footer        string `json:"footer"`
+	ClippedSince  *int64 `json:"clipped_since"`
+	Original      string `json:"original"`
+	Timezone      string `json:"timezone"`
+	Abbrev        string `json:"abbrev"`
+	Offset        int64  `json:"offset"`
+	IsDST         bool   `json:"is_dst"`
+	IsStd         bool   `json:"is_std"`
+	IsUT          bool   `json:"is_ut"`
+	Start         int64  `json:"start"`
+	End           int64  `json:"end"`
+}
+
+// ReadExport reads data written by WriteJSON (nested or flat) or WriteNDJSON.
+// The format is recognized by the fields of the first JSON object.
+func ReadExport(r io.Reader) (*Export, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	var first map[string]json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return nil, fmt.Errorf("tzdb: cannot read export: %s", err)
+	}
+	data, _ := json.Marshal(first)
+
+	switch {
+	case first["timezones"] != nil:
+		var export Export
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, fmt.Errorf("tzdb: cannot read export: %s", err)
+		}
+		return &export, nil
+
+	case first["originals"] != nil:
+		var flat struct {
+			Originals []exportRow `json:"originals"`
+			Replicas  []exportRow `json:"replicas"`
+			Zones     []exportRow `json:"zones"`
+		}
+		if err := json.Unmarshal(data, &flat); err != nil {
+			return nil, fmt.Errorf("tzdb: cannot read export: %s", err)
+		}
+		return assembleExport(flat.Originals, flat.Replicas, flat.Zones)
+
+	case first["table"] != nil:
+		tables := map[string][]exportRow{}
+		for row := data; ; {
+			var r exportRow
+			if err := json.Unmarshal(row, &r); err != nil {
+				return nil, fmt.Errorf("tzdb: cannot read export: %s", err)
+			}
+			tables[r.Table] = append(tables[r.Table], r)
+
+			var next json.RawMessage
+			if err := decoder.Decode(&next); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("tzdb: cannot read export: %s", err)
+			}
+			row = next
+		}
+		return assembleExport(tables["original"], tables["replica"], tables["zone"])
+	}
+
+	return nil, fmt.Errorf("tzdb: cannot read export: unknown format")
+}
+
+// ReadExportCSV reads data written by WriteCSV in the specified directory.
+func ReadExportCSV(dir string) (*Export, error) {
+	tables := make([][]exportRow, 3)
+	for i, table := range []struct {
+		file    string
+		columns []string
+	}{
+		{"originals.csv", ExportOriginalCols},
+		{"replicas.csv", ExportReplicaCols},
+		{"zones.csv", ExportZoneCols},
+	} {
+		rows, err := readCSVFile(filepath.Join(dir, table.file), table.columns)
+		if err != nil {
+			return nil, fmt.Errorf("tzdb: cannot read %s: %s", table.file, err)
+		}
+		tables[i] = rows
+	}
+
+	return assembleExport(tables[0], tables[1], tables[2])
+}
+
+// optionalCSVCols are the columns that files written by earlier
+// versions lack; rows of such files get their zero values.
+var optionalCSVCols = map[string]bool{"clipped_since": true, "is_std": true, "is_ut": true}
+
+// readCSVFile reads the rows of a table. Columns are recognized by
+// the names in the first line, so their order does not matter.
+func readCSVFile(filename string, columns []string) ([]exportRow, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(bufio.NewReader(file))
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[column] = i
+	}
+	present := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if _, ok := index[column]; ok {
+			present = append(present, column)
+		} else if !optionalCSVCols[column] {
+			return nil, fmt.Errorf("missing column %q", column)
+		}
+	}
+
+	rows := make([]exportRow, 0, 1000)
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var row exportRow
+		values := map[string]interface{}{
+			"name": &row.Name, "default_zone": &row.DefaultZone, "default_offset": &row.DefaultOffset,
+			"tzdata_version": &row.TZDataVersion, "footer": &row.Footer, "clipped_since": &row.ClippedSince, "original": &row.Original,
+			"timezone": &row.Timezone, "abbrev": &row.Abbrev, "offset": &row.Offset,
+			"is_dst": &row.IsDST, "is_std": &row.IsStd, "is_ut": &row.IsUT, "start": &row.Start, "end": &row.End}
+		for _, column := range present {
+			text := record[index[column]]
+			switch value := values[column].(type) {
+			case *string:
+				*value = text
+			case *int64:
+				if *value, err = strconv.ParseInt(text, 10, 64); err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, column, text)
+				}
+			case *bool:
+				if *value, err = strconv.ParseBool(text); err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, column, text)
+				}
+			case **int64:
+				// empty if missing
+				if text == "" {
+					continue
+				}
+				number, err := strconv.ParseInt(text, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, column, text)
+				}
+				*value = &number
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// assembleExport groups the rows of each table by original timezone.
+// The version of tzdata of the export is the most recent one of its originals.
+func assembleExport(originals, replicas, zones []exportRow) (*Export, error) {
+	export := &Export{Timezones: make([]ExportedTimezone, 0, len(originals))}
+	index := make(map[string]int, len(originals))
+	for _, row := range originals {
+		index[row.Name] = len(export.Timezones)
+		export.Timezones = append(export.Timezones, ExportedTimezone{
+			Name:          row.Name,
+			DefaultZone:   row.DefaultZone,
+			DefaultOffset: row.DefaultOffset,
+			TZDataVersion: row.TZDataVersion,
+			Footer:        row.Footer,
+			ClippedSince:  row.ClippedSince,
+			Replicas:      make([]string, 0),
+			Zones:         make([]ExportedZone, 0)})
+		if row.TZDataVersion > export.TZDataVersion {
+			export.TZDataVersion = row.TZDataVersion
+		}
+	}
+
+	for _, row := range replicas {
+		i, ok := index[row.Original]
+		if !ok {
+			return nil, fmt.Errorf("tzdb: replica %q links to %q, which is not exported", row.Name, row.Original)
+		}
+		export.Timezones[i].Replicas = append(export.Timezones[i].Replicas, row.Name)
+	}
+
+	for _, row := range zones {
+		i, ok := index[row.Timezone]
+		if !ok {
+			return nil, fmt.Errorf("tzdb: zone of %q, which is not exported", row.Timezone)
+		}
+		export.Timezones[i].Zones = append(export.Timezones[i].Zones, ExportedZone{Abbrev: row.Abbrev,
+			Offset: row.Offset, IsDST: row.IsDST, IsStd: row.IsStd, IsUT: row.IsUT, Start: row.Start, End: row.End})
+	}
+
+	sort.SliceStable(export.Timezones, func(i, j int) bool {
+		return export.Timezones[i].Name < export.Timezones[j].Name
+	})
+
+	return export, nil
+}
+
+// ImportedZones converts the zones of an exported timezone back to zones.
+func (tz *ExportedTimezone) ImportedZones() []Zone {
+	zones := make([]Zone, 0, len(tz.Zones))
+	for _, zone := range tz.Zones {
+		zones = append(zones, Zone{Name: zone.Abbrev, Offset: zone.Offset, IsDST: zone.IsDST,
+			IsStd: zone.IsStd, IsUT: zone.IsUT, Start: zone.Start, End: zone.End})
+	}
+	return zones
+}
+
+// ImportChange is the change of an original, as applied by ApplyImport.
+type ImportChange struct {
+	Original *Original  // stored as with UpdateOriginal
+	Zones    []Zone     // added to the table of zones of Original, unless nil
+	Audit    AuditEntry // recorded along with the change
+}
+
+// ApplyImport stores the changes of an import within a single transaction,
+// so that the database is left as it was if any of them fails. The changes
+// start a new generation of data, as those of UpdateOriginal do.
+func ApplyImport(changes []ImportChange) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, change := range changes {
+		if err := updateOriginal(tx, change.Original); err != nil {
+			return fmt.Errorf("tzdb: cannot update %q: %s", change.Original.Name, err)
+		}
+		if change.Zones != nil {
+			// tables of zones are named after the original, as in UpdateOriginal
+			tableName, err := makeTabName(change.Original.Name)
+			if err != nil {
+				return err
+			}
+			table := fmt.Sprintf("%s%v", tableName, change.Original.TabVer)
+			if err := addZones(tx, table, change.Zones); err != nil {
+				return fmt.Errorf("tzdb: cannot add zones of %q: %s", change.Original.Name, err)
+			}
+		}
+		if err := addAudit(tx, change.Audit); err != nil {
+			return fmt.Errorf("tzdb: cannot record import of %q: %s", change.Original.Name, err)
+		}
+	}
+
+	// readers learn of the import along with its changes
+	if len(changes) != 0 {
+		if err := bumpGeneration(tx); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CheckImport validates exported data before they are imported in the
+// open database. Only originals that are already stored can be imported.
+// Their zones should pass the same checks as a table of zones does in
+// Check, and should not start later than the stored ones, which would
+// drop part of the history (e.g. when exported with a time range).
+func CheckImport(export *Export) ([]Problem, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	problems := make([]Problem, 0)
+	seen := make(map[string]bool, len(export.Timezones))
+	for _, tz := range export.Timezones {
+		if seen[tz.Name] {
+			problems = append(problems, Problem{Timezone: tz.Name, Kind: ProblemDuplicate,
+				Detail: "original appears more than once"})
+			continue
+		}
+		seen[tz.Name] = true
+
+		columns := getOriginalCols()
+		original, err := queryOriginal(fmt.Sprintf("SELECT * FROM %s WHERE %s=?", originalTable, columns[1]), tz.Name)
+		if err != nil {
+			problems = append(problems, Problem{Timezone: tz.Name, Kind: ProblemUnknown,
+				Detail: "no such original in database"})
+			continue
+		}
+
+		zones := tz.ImportedZones()
+		for _, detail := range checkZones(zones) {
+			problems = append(problems, Problem{Timezone: tz.Name, Kind: ProblemZones, Detail: detail})
+		}
+
+		stored, err := getActiveZones(original)
+		if err != nil || len(stored) == 0 {
+			continue
+		}
+		switch {
+		case len(zones) == 0:
+			problems = append(problems, Problem{Timezone: tz.Name, Kind: ProblemTruncated,
+				Detail: fmt.Sprintf("no zones, while %d are stored", len(stored))})
+		case zones[0].Start > stored[0].Start:
+			problems = append(problems, Problem{Timezone: tz.Name, Kind: ProblemTruncated,
+				Detail: fmt.Sprintf("zones start at %d, while stored ones start at %d", zones[0].Start, stored[0].Start)})
+		}
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,85 @@
+package tzdb
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// locationCache keeps the locations built by LoadLocation, as long as
+// neither the version of TZ-data nor the generation of data change.
+var locationCache struct {
+	sync.Mutex
+	version    string
+	generation int64
+	locations  map[string]*time.Location
+}
+
+// LoadLocation builds a time.Location for the specified timezone,
+// from the active table of zones and the footer of its original.
+// The specified timezone is treated as a replica (link), as in GetZones,
+// but the returned location carries the specified name. Before the first
+// stored transition, the zone of that transition is assumed to be in effect,
+// unless zones were clipped or are shared since an instant: local time
+// before them is unknown ("-00").
+// Locations are cached until the data of the database change, including
+// changes that keep the version of TZ-data, as with overrides or an import.
+func LoadLocation(name string) (*time.Location, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	version, err := getTZDataVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	generation, err := getGeneration(db)
+	if err != nil {
+		return nil, err
+	}
+
+	locationCache.Lock()
+	defer locationCache.Unlock()
+
+	if locationCache.version != version || locationCache.generation != generation || locationCache.locations == nil {
+		locationCache.version = version
+		locationCache.generation = generation
+		locationCache.locations = make(map[string]*time.Location)
+	}
+	if location, ok := locationCache.locations[name]; ok {
+		return location, nil
+	}
+
+	original, err := getOriginal(name)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := effectiveZones(original)
+	if err != nil {
+		return nil, err
+	}
+
+	// the history of clipped originals starts with their first zone, which
+	// is kept even if it starts before Since, and that of originals sharing
+	// the table of another one with the first zone since SharedSince
+	since := int64(math.MinInt64)
+	if (original.Clipped || original.Shared) && len(zones) != 0 {
+		since = zones[0].Start
+	}
+	data, err := encodeTZif(zones, original.Footer, since)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocationFromTZData(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	locationCache.locations[name] = location
+	return location, nil
+}
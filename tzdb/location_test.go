@@ -0,0 +1,167 @@
+package tzdb
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEncodeTZif(t *testing.T) {
+	zones := []Zone{
+		{Name: "LMT", Offset: 5692, Start: math.MinInt64, End: -2344642493},
+		{Name: "AMT", Offset: 5692, Start: -2344642492, End: -1686101633},
+		{Name: "EET", Offset: 7200, Start: -1686101632, End: -1182996001},
+		{Name: "EEST", Offset: 10800, IsDST: true, Start: -1182996000, End: -1178161201},
+		{Name: "EET", Offset: 7200, Start: -1178161200, End: -1},
+	}
+	data, err := encodeTZif(zones, "EET-2EEST,M3.5.0/3,M10.5.0/4", math.MinInt64)
+	if err != nil {
+		t.Fatalf("cannot encode zones: %s", err)
+	}
+	location, err := time.LoadLocationFromTZData("Europe/Athens", data)
+	if err != nil {
+		t.Fatalf("cannot load encoded data: %s", err)
+	}
+
+	for _, test := range []struct {
+		sec    int64
+		abbrev string
+		offset int
+		isDST  bool
+	}{
+		{-2500000000, "LMT", 5692, false},
+		{-2344642492, "AMT", 5692, false},
+		{-1182996001, "EET", 7200, false},
+		{-1182996000, "EEST", 10800, true},
+		{-1170000000, "EET", 7200, false}, // after last transition, from footer
+		{1593561600, "EEST", 10800, true}, // 2020-07-01, from footer
+		{1609459200, "EET", 7200, false},  // 2021-01-01, from footer
+	} {
+		at := time.Unix(test.sec, 0).In(location)
+		abbrev, offset := at.Zone()
+		if abbrev != test.abbrev || offset != test.offset || at.IsDST() != test.isDST {
+			t.Errorf("at %d got %s %d dst=%v, want %s %d dst=%v", test.sec, abbrev, offset, at.IsDST(), test.abbrev, test.offset, test.isDST)
+		}
+	}
+}
+
+func TestEncodeTZifSince(t *testing.T) {
+	zones := []Zone{
+		{Name: "AMT", Offset: 5692, Start: -2344642492, End: -1686101633},
+		{Name: "EET", Offset: 7200, Start: -1686101632, End: -1182996001},
+		{Name: "EEST", Offset: 10800, IsDST: true, Start: -1182996000, End: -1},
+	}
+	// local time is unknown before the middle of the EET zone
+	data, err := encodeTZif(zones, "", -1500000000)
+	if err != nil {
+		t.Fatalf("cannot encode zones: %s", err)
+	}
+	location, err := time.LoadLocationFromTZData("Europe/Athens", data)
+	if err != nil {
+		t.Fatalf("cannot load encoded data: %s", err)
+	}
+
+	for _, test := range []struct {
+		sec    int64
+		abbrev string
+		offset int
+	}{
+		{-2000000000, "-00", 0},
+		{-1500000001, "-00", 0},
+		{-1500000000, "EET", 7200},
+		{-1182996000, "EEST", 10800},
+	} {
+		if abbrev, offset := time.Unix(test.sec, 0).In(location).Zone(); abbrev != test.abbrev || offset != test.offset {
+			t.Errorf("at %d got %s %d, want %s %d", test.sec, abbrev, offset, test.abbrev, test.offset)
+		}
+	}
+}
+
+func TestEncodeTZifIndicators(t *testing.T) {
+	zones := []Zone{
+		{Name: "EET", Offset: 7200, Start: math.MinInt64, End: 354675599},
+		{Name: "EEST", Offset: 10800, IsDST: true, IsStd: true, IsUT: true, Start: 354675600, End: 370400399},
+		{Name: "EET", Offset: 7200, IsStd: true, IsUT: true, Start: 370400400, End: -1},
+	}
+	data, err := encodeTZif(zones, "EET-2EEST,M3.5.0/3,M10.5.0/4", math.MinInt64)
+	if err != nil {
+		t.Fatalf("cannot encode zones: %s", err)
+	}
+	if _, err := time.LoadLocationFromTZData("Europe/Athens", data); err != nil {
+		t.Fatalf("cannot load encoded data: %s", err)
+	}
+
+	// counts of version 2 follow the header and data block of version 1
+	counts := data[44+7+20:]
+	isutcnt := binary.BigEndian.Uint32(counts[0:])
+	isstdcnt := binary.BigEndian.Uint32(counts[4:])
+	typecnt := binary.BigEndian.Uint32(counts[16:])
+	if typecnt != 3 || isutcnt != typecnt || isstdcnt != typecnt {
+		t.Errorf("got isutcnt %d, isstdcnt %d and typecnt %d, want 3 of each", isutcnt, isstdcnt, typecnt)
+	}
+
+	// without indicators set, none are written
+	for i := range zones {
+		zones[i].IsStd, zones[i].IsUT = false, false
+	}
+	data, err = encodeTZif(zones, "", math.MinInt64)
+	if err != nil {
+		t.Fatalf("cannot encode zones: %s", err)
+	}
+	counts = data[44+7+20:]
+	if isutcnt, isstdcnt := binary.BigEndian.Uint32(counts[0:]), binary.BigEndian.Uint32(counts[4:]); isutcnt != 0 || isstdcnt != 0 {
+		t.Errorf("got isutcnt %d and isstdcnt %d without indicators, want none", isutcnt, isstdcnt)
+	}
+}
+
+func TestEncodeTZifTooManyTypes(t *testing.T) {
+	zones := make([]Zone, 0, 300)
+	for i := 0; i < 300; i++ {
+		zones = append(zones, Zone{Name: "AAA", Offset: int64(i), Start: int64(i) * 3600, End: int64(i)*3600 + 3599})
+	}
+	zones[len(zones)-1].End = -1
+	if _, err := encodeTZif(zones, "", math.MinInt64); err == nil {
+		t.Errorf("encoded %d types, want error", len(zones))
+	}
+	if _, err := encodeTZif(zones[:256], "", math.MinInt64); err != nil {
+		t.Errorf("cannot encode 256 types: %s", err)
+	}
+}
+
+func TestLoadLocationAfterChange(t *testing.T) {
+	openTestDB(t, "Europe/Athens")
+
+	future := time.Date(2100, 1, 15, 12, 0, 0, 0, time.UTC)
+	athens, err := LoadLocation("Europe/Athens")
+	if err != nil {
+		t.Fatalf("LoadLocation failed: %s", err)
+	}
+	if _, offset := future.In(athens).Zone(); offset != 7200 {
+		t.Fatalf("offset of Athens in 2100 = %d, want 7200", offset)
+	}
+	before, err := GetGeneration()
+	if err != nil {
+		t.Fatalf("GetGeneration failed: %s", err)
+	}
+
+	// a change that keeps the version of TZ-data, as an override would
+	original, err := GetOriginalByName("Europe/Athens")
+	if err != nil {
+		t.Fatalf("cannot find Athens: %s", err)
+	}
+	original.Footer = "<+05>-5"
+	if err := UpdateOriginal(original); err != nil {
+		t.Fatalf("UpdateOriginal failed: %s", err)
+	}
+
+	if after, err := GetGeneration(); err != nil || after <= before {
+		t.Errorf("generation after UpdateOriginal = %d, %v, want more than %d", after, err, before)
+	}
+	if athens, err = LoadLocation("Europe/Athens"); err != nil {
+		t.Fatalf("LoadLocation failed: %s", err)
+	}
+	if _, offset := future.In(athens).Zone(); offset != 18000 {
+		t.Errorf("offset of Athens in 2100 after change = %d, want 18000", offset)
+	}
+}
@@ -0,0 +1,147 @@
+package tzdb
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Observation is an offset from UTC seen at an instant, optionally
+// along with the abbreviation of the zone, e.g. from a timestamp
+// that carries an offset but no timezone.
+type Observation struct {
+	At     int64  // seconds since January 1, 1970 UTC
+	Offset int64  // seconds east of UTC
+	Abbrev string // ignored if empty
+}
+
+// OffsetMatch is a timezone matching a set of observations.
+type OffsetMatch struct {
+	Timezone  string   // original timezone
+	Countries []string // countries the timezone is used in, as in zone.tab
+	Hinted    bool     // used in the country given as a hint
+}
+
+// MatchOffsets finds the original timezones whose zones match all of the
+// observations. Since the zoneinfo source has no population data, matches
+// are ranked by the country given as a hint (an ISO 3166 alpha-2 code, may
+// be empty), then by their position in zone.tab, which lists the timezones
+// of each country most populous first. Timezones not used in any country
+// (e.g. Etc/GMT-3) come last.
+func MatchOffsets(observations []Observation, countryHint string) ([]OffsetMatch, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+	if len(observations) == 0 {
+		return nil, errors.New("tzdb: no observations to match")
+	}
+
+	originals, err := getOriginals()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]OffsetMatch, 0, 20)
+	for i := range originals {
+		zones, err := effectiveZones(&originals[i])
+		if err != nil {
+			return nil, err
+		}
+		if matchZones(zones, observations) {
+			matches = append(matches, OffsetMatch{Timezone: originals[i].Name})
+		}
+	}
+	if len(matches) == 0 {
+		return matches, nil
+	}
+
+	// rank by country, through the originals of the timezones of zone.tab
+	replicas, err := getReplicas()
+	if err != nil {
+		return nil, err
+	}
+	originalNames := make(map[int64]string, len(originals))
+	for _, original := range originals {
+		originalNames[original.ID] = original.Name
+	}
+	originalOf := make(map[string]string, len(replicas))
+	for _, replica := range replicas {
+		originalOf[replica.Name] = originalNames[replica.ProtoID]
+	}
+
+	countryZones, err := getCountryZones("ORDER BY " + getCountryZoneCols()[0])
+	if err != nil {
+		return nil, err
+	}
+	position := make(map[string]int)  // position of original within its country
+	positions := make(map[string]int) // timezones seen per country
+	countries := make(map[string][]string)
+	hinted := make(map[string]int)
+	countryHint = strings.ToUpper(countryHint)
+	for _, cz := range countryZones {
+		name := originalOf[cz.Timezone]
+		if name == "" {
+			name = cz.Timezone
+		}
+		if p, ok := position[name]; !ok || positions[cz.Country] < p {
+			position[name] = positions[cz.Country]
+		}
+		positions[cz.Country]++
+		if !containsString(countries[name], cz.Country) {
+			countries[name] = append(countries[name], cz.Country)
+		}
+		if cz.Country == countryHint {
+			if _, ok := hinted[name]; !ok {
+				hinted[name] = len(hinted)
+			}
+		}
+	}
+
+	for i := range matches {
+		name := matches[i].Timezone
+		matches[i].Countries = countries[name]
+		_, matches[i].Hinted = hinted[name]
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Hinted != b.Hinted {
+			return a.Hinted
+		}
+		if a.Hinted {
+			return hinted[a.Timezone] < hinted[b.Timezone]
+		}
+		if (len(a.Countries) == 0) != (len(b.Countries) == 0) {
+			return len(a.Countries) != 0
+		}
+		if position[a.Timezone] != position[b.Timezone] {
+			return position[a.Timezone] < position[b.Timezone]
+		}
+		return a.Timezone < b.Timezone
+	})
+
+	return matches, nil
+}
+
+// matchZones checks whether the zones of a timezone match all observations.
+func matchZones(zones []Zone, observations []Observation) bool {
+	for _, o := range observations {
+		zone, ok := LookupZone(zones, o.At)
+		if !ok || zone.Offset != o.Offset || (o.Abbrev != "" && zone.Name != o.Abbrev) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
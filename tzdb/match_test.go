@@ -0,0 +1,40 @@
+package tzdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchOffsets(t *testing.T) {
+	openTestDB(t, "Asia/Nicosia", "Europe/Athens", "Europe/London")
+
+	err := SetCountryZones([]Country{{"CY", "Cyprus"}, {"GR", "Greece"}}, []CountryZone{
+		{Timezone: "Asia/Nicosia", Country: "CY"},
+		{Timezone: "Europe/Athens", Country: "GR"}})
+	if err != nil {
+		t.Fatalf("SetCountryZones failed: %s", err)
+	}
+
+	winter := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC).Unix()
+	summer := time.Date(2020, 7, 15, 12, 0, 0, 0, time.UTC).Unix()
+	observations := []Observation{{At: winter, Offset: 7200, Abbrev: "EET"}, {At: summer, Offset: 10800}}
+
+	for _, hint := range []string{"GR", "cy"} {
+		matches, err := MatchOffsets(observations, hint)
+		if err != nil || len(matches) < 2 {
+			t.Fatalf("MatchOffsets(%q) = %v, %v", hint, matches, err)
+		}
+		if want := map[string]string{"GR": "Europe/Athens", "cy": "Asia/Nicosia"}[hint]; matches[0].Timezone != want || !matches[0].Hinted {
+			t.Errorf("MatchOffsets(%q) ranked %q first, want %q", hint, matches[0].Timezone, want)
+		}
+		if matches[1].Hinted || len(matches[1].Countries) != 1 {
+			t.Errorf("MatchOffsets(%q) ranked %+v second", hint, matches[1])
+		}
+	}
+
+	// no timezone observes EEST in winter
+	matches, err := MatchOffsets([]Observation{{At: winter, Offset: 10800, Abbrev: "EEST"}}, "")
+	if err != nil || len(matches) != 0 {
+		t.Errorf("MatchOffsets of impossible observation = %v, %v", matches, err)
+	}
+}
@@ -0,0 +1,280 @@
+package tzdb
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SetMetazones replaces the stored usage of metazones.
+func SetMetazones(usages []MetazoneUsage) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", metazoneTable)); err != nil {
+		return err
+	}
+
+	fields := getMetazoneCols()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES(?, ?, ?, ?)",
+		metazoneTable, fields[1], fields[2], fields[3], fields[4])
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, usage := range usages {
+		if _, err := stmt.Exec(usage.Timezone, usage.Metazone, usage.From, usage.To); err != nil {
+			return err
+		}
+	}
+
+	if err := bumpGeneration(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetZoneNames replaces the stored display names of a locale.
+func SetZoneNames(locale string, names []ZoneNames) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fields := getZoneNamesCols()
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s=?", zoneNamesTable, fields[1]), locale); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		zoneNamesTable, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7], fields[8], fields[9])
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, n := range names {
+		_, err := stmt.Exec(locale, n.Kind, n.Name, n.LongGeneric, n.LongStandard, n.LongDaylight,
+			n.ShortGeneric, n.ShortStandard, n.ShortDaylight)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := bumpGeneration(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLocales retrieves the locales that display names are stored for.
+func GetLocales() ([]string, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	locales := make([]string, 0, 10)
+	if !tableDefined(zoneNamesTable) {
+		return locales, nil
+	}
+
+	fields := getZoneNamesCols()
+	rows, err := db.Query(fmt.Sprintf("SELECT DISTINCT %s FROM %s ORDER BY %s", fields[1], zoneNamesTable, fields[1]))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var locale string
+		if err := rows.Scan(&locale); err != nil {
+			return nil, err
+		}
+		locales = append(locales, locale)
+	}
+
+	return locales, rows.Err()
+}
+
+// DisplayName returns the localized long name of a timezone at an instant,
+// e.g. "Central European Summer Time" for Europe/Berlin in July, locale "en".
+// The daylight or standard form is picked by the zone in effect at the instant.
+// Locales are given as in CLDR (e.g. en_GB or en-GB) and fall back to their
+// parent locale (e.g. en), if they lack a name.
+func DisplayName(timezone, locale string, sec int64) (string, error) {
+	return displayName(timezone, locale, sec, false)
+}
+
+// ShortDisplayName returns the localized short name of a timezone at an
+// instant, e.g. "GMT" or "BST" for Europe/London, locale "en_GB". Since
+// few short names are given by CLDR, the long name is returned otherwise.
+func ShortDisplayName(timezone, locale string, sec int64) (string, error) {
+	name, err := displayName(timezone, locale, sec, true)
+	if err != nil {
+		return displayName(timezone, locale, sec, false)
+	}
+	return name, nil
+}
+
+// displayName picks a name given for the timezone itself, which CLDR gives
+// for exceptions (e.g. British Summer Time), or else a name of the metazone
+// in use at the instant. Names given by CLDR for other names of the timezone,
+// such as its original or other replicas, are used as well.
+func displayName(timezone, locale string, sec int64, short bool) (string, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return "", noDB
+	}
+
+	original, err := getOriginal(timezone)
+	if err != nil {
+		return "", err
+	}
+	zones, err := effectiveZones(original)
+	if err != nil {
+		return "", err
+	}
+	zone, ok := LookupZone(zones, sec)
+	if !ok {
+		return "", fmt.Errorf("tzdb: no zone of %q in effect at %d", timezone, sec)
+	}
+
+	aliases := []string{timezone}
+	if original.Name != timezone {
+		aliases = append(aliases, original.Name)
+	}
+	replicas, err := getReplicasOf(original.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, replica := range replicas {
+		if replica != timezone && replica != original.Name {
+			aliases = append(aliases, replica)
+		}
+	}
+
+	locales := localeChain(locale)
+	for _, alias := range aliases {
+		for _, loc := range locales {
+			names, err := getZoneNames(loc, "zone", alias)
+			if err != nil {
+				return "", err
+			}
+			if name := names.pick(zone.IsDST, short); name != "" {
+				return name, nil
+			}
+		}
+	}
+
+	for _, alias := range aliases {
+		metazone, err := getMetazone(alias, sec)
+		if err != nil {
+			return "", err
+		}
+		if metazone == "" {
+			continue
+		}
+		for _, loc := range locales {
+			names, err := getZoneNames(loc, "metazone", metazone)
+			if err != nil {
+				return "", err
+			}
+			if name := names.pick(zone.IsDST, short); name != "" {
+				return name, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("tzdb: no display name of %q for locale %q", timezone, locale)
+}
+
+// pick returns the daylight or standard form of a name. The generic
+// form is used only if neither of the specific forms is given.
+func (n ZoneNames) pick(isDST, short bool) string {
+	generic, standard, daylight := n.LongGeneric, n.LongStandard, n.LongDaylight
+	if short {
+		generic, standard, daylight = n.ShortGeneric, n.ShortStandard, n.ShortDaylight
+	}
+
+	if standard == "" && daylight == "" {
+		return generic
+	}
+	if isDST {
+		return daylight
+	}
+	return standard
+}
+
+// localeChain returns a locale followed by its parents, e.g.
+// en_GB and en for en-GB. The root locale has no names.
+func localeChain(locale string) []string {
+	locale = strings.Replace(locale, "-", "_", -1)
+	chain := make([]string, 0, 3)
+	for locale != "" {
+		chain = append(chain, locale)
+		i := strings.LastIndex(locale, "_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return chain
+}
+
+// getZoneNames retrieves the names of a metazone or timezone in a locale.
+// Databases without a table of display names have none.
+func getZoneNames(locale, kind, name string) (ZoneNames, error) {
+	var n ZoneNames
+	if !tableDefined(zoneNamesTable) {
+		return n, nil
+	}
+
+	fields := getZoneNamesCols()
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s=? AND %s=? AND %s=?", zoneNamesTable, fields[1], fields[2], fields[3])
+	err := db.QueryRow(query, locale, kind, name).Scan(&n.ID, &n.Locale, &n.Kind, &n.Name,
+		&n.LongGeneric, &n.LongStandard, &n.LongDaylight, &n.ShortGeneric, &n.ShortStandard, &n.ShortDaylight)
+	if err == sql.ErrNoRows {
+		return ZoneNames{}, nil
+	}
+	return n, err
+}
+
+// getMetazone retrieves the metazone used by a timezone at an instant, if any.
+func getMetazone(timezone string, sec int64) (string, error) {
+	if !tableDefined(metazoneTable) {
+		return "", nil
+	}
+
+	fields := getMetazoneCols()
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s=? AND %s<=? AND (%s=-1 OR %s>?)",
+		fields[2], metazoneTable, fields[1], fields[3], fields[4], fields[4])
+
+	var metazone string
+	err := db.QueryRow(query, timezone, sec, sec).Scan(&metazone)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return metazone, err
+}
@@ -0,0 +1,75 @@
+package tzdb
+
+import (
+	"fmt"
+)
+
+// SetOverride records an override, replacing any stored one of the same name.
+func SetOverride(o Override) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	fields := getOverrideCols()
+	query := fmt.Sprintf("INSERT OR REPLACE INTO %s (%s, %s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?, ?)",
+		overrideTable, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6])
+
+	stmt, err := db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(o.Name, o.Timezone, o.Definition, o.TZDVer, o.Status, o.Time)
+	return err
+}
+
+// RemoveOverride removes the record of the named override, if any.
+func RemoveOverride(name string) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	fields := getOverrideCols()
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s=?", overrideTable, fields[1])
+
+	_, err := db.Exec(query, name)
+	return err
+}
+
+// GetOverrides retrieves all recorded overrides, sorted by name.
+// Databases without a table of overrides have none.
+func GetOverrides() (overrides []Override, err error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	fields := getOverrideCols()
+	query := fmt.Sprintf("SELECT name FROM sqlite_master WHERE type='table' AND name='%s'", overrideTable)
+	var name string
+	if err := db.QueryRow(query).Scan(&name); err != nil {
+		return []Override{}, nil
+	}
+
+	query = fmt.Sprintf("SELECT * FROM %s ORDER BY %s", overrideTable, fields[1])
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides = make([]Override, 0, 10)
+	for rows.Next() {
+		var o Override
+		err = rows.Scan(&o.ID, &o.Name, &o.Timezone, &o.Definition, &o.TZDVer, &o.Status, &o.Time)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+
+	return overrides, rows.Err()
+}
@@ -0,0 +1,157 @@
+package tzdb
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// watcher keeps track of the file of a database opened with OpenWatched.
+type watcher struct {
+	filename   string
+	info       os.FileInfo
+	version    string
+	generation int64
+	onChange   func(oldVersion, newVersion string)
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+var (
+	watching  *watcher
+	watchLock sync.Mutex
+)
+
+// OpenWatched opens the specified database in read-only mode, like OpenRO,
+// and checks it for changes at the specified interval. When the file is
+// replaced or modified, a new connection is opened and swapped in place
+// of the current one. Lookups in progress complete with the connection
+// they started with. If the connection is swapped, or the version of TZ-data
+// or the generation of data changes, onChange (if not nil) is called with
+// the old and the new version, which may be the same; it must not call Close.
+// Watching stops with Close.
+func OpenWatched(filename string, interval time.Duration, onChange func(oldVersion, newVersion string)) error {
+	if err := OpenRO(filename); err != nil {
+		return err
+	}
+
+	// OpenRO does not actually touch the file,
+	// so make sure it is a readable database.
+	version, err := GetTZDataVersion()
+	if err != nil {
+		Close()
+		return err
+	}
+	generation, err := GetGeneration()
+	if err != nil {
+		Close()
+		return err
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		Close()
+		return err
+	}
+
+	w := &watcher{
+		filename:   filename,
+		info:       info,
+		version:    version,
+		generation: generation,
+		onChange:   onChange,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{})}
+
+	watchLock.Lock()
+	watching = w
+	watchLock.Unlock()
+
+	go w.run(interval)
+	return nil
+}
+
+func (w *watcher) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll swaps the connection if the file has changed and reports
+// swaps and changes of the version or the generation. Failures are
+// ignored, since the file may be in the middle of being
+// replaced; the check is simply repeated at the next poll.
+func (w *watcher) poll() {
+	info, err := os.Stat(w.filename)
+	if err != nil {
+		return
+	}
+
+	var version string
+	var generation int64
+	swapped := false
+	if os.SameFile(info, w.info) && info.ModTime() == w.info.ModTime() && info.Size() == w.info.Size() {
+		// data may still have been updated in place (e.g. in the WAL)
+		if version, err = GetTZDataVersion(); err != nil {
+			return
+		}
+		if generation, err = GetGeneration(); err != nil {
+			return
+		}
+	} else {
+		conn, err := openRO(w.filename)
+		if err != nil {
+			return
+		}
+		if version, err = getTZDataVersion(conn); err != nil {
+			conn.Close()
+			return
+		}
+		if generation, err = getGeneration(conn); err != nil {
+			conn.Close()
+			return
+		}
+
+		dbLock.Lock()
+		old := db
+		db = conn
+		dbLock.Unlock()
+
+		// waits for queries in progress to finish
+		old.Close()
+		w.info = info
+		swapped = true
+	}
+
+	if swapped || version != w.version || generation != w.generation {
+		oldVersion := w.version
+		w.version = version
+		w.generation = generation
+		if w.onChange != nil {
+			w.onChange(oldVersion, version)
+		}
+	}
+}
+
+// stopWatching stops the watcher started by OpenWatched, if any.
+func stopWatching() {
+	watchLock.Lock()
+	w := watching
+	watching = nil
+	watchLock.Unlock()
+
+	if w != nil {
+		close(w.stop)
+		<-w.done
+	}
+}
@@ -1,9 +1,19 @@
 package tzdb
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 )
 
+// dbConn is the part of *sql.DB that setters use, which
+// *sql.Tx also provides, so that they can run within a transaction.
+type dbConn interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Prepare(query string) (*sql.Stmt, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // UpdateOriginal updates data of an existing entry in original timezones table.
 // This function is mainly used during initial setup, after having parsed
 // and processed the respective timezone file.
@@ -12,12 +22,20 @@ func UpdateOriginal(origTZ *Original) error {
 		return noDB
 	}
 
+	if err := updateOriginal(db, origTZ); err != nil {
+		return err
+	}
+
+	return bumpGeneration(db)
+}
+
+func updateOriginal(conn dbConn, origTZ *Original) error {
 	fields := getOriginalCols()
-	query := fmt.Sprintf("UPDATE %q SET %q=?, %q=?, %q=?, %q=?, %q=? WHERE %q=%q",
+	query := fmt.Sprintf("UPDATE %q SET %q=?, %q=?, %q=?, %q=?, %q=?, %q=?, %q=?, %q=NULL WHERE %q=%q",
 		originalTable, fields[2], fields[3], fields[4], fields[5],
-		fields[6], fields[1], origTZ.Name)
+		fields[6], fields[7], fields[8], fields[9], fields[1], origTZ.Name)
 
-	stmt, err := db.Prepare(query)
+	stmt, err := conn.Prepare(query)
 	if err != nil {
 		return err
 	}
@@ -29,7 +47,15 @@ func UpdateOriginal(origTZ *Original) error {
 		return err
 	}
 
-	_, err = stmt.Exec(origTZ.DZone, origTZ.DOffset, tableName, origTZ.TabVer, origTZ.TZDVer)
+	// the boundary of clipping is NULL, unless zones were clipped,
+	// and that of sharing is always NULL, as the original gets its
+	// own table of zones
+	var since interface{}
+	if origTZ.Clipped {
+		since = origTZ.Since
+	}
+
+	_, err = stmt.Exec(origTZ.DZone, origTZ.DOffset, tableName, origTZ.TabVer, origTZ.TZDVer, origTZ.Footer, since)
 	return err
 }
 
@@ -70,7 +96,7 @@ func AddOriginal(originalTZ string) (id int64, err error) {
 		return -1, err
 	}
 
-	return id, nil
+	return id, bumpGeneration(db)
 }
 
 // AddReplicas adds a new list of entries in the preplicas' table.
@@ -98,12 +124,17 @@ func AddReplicas(replicaTZs []string, originalTZ string) error {
 	defer stmt.Close()
 
 	// add each replica with the ID of the specified origial TZ
+	added := false
 	for _, replicaTZ := range replicaTZs {
-		stmt.Exec(replicaTZ, id)
+		_, err := stmt.Exec(replicaTZ, id)
 		// ignore errors at this point
+		added = added || err == nil
 	}
 
-	return nil
+	if !added {
+		return nil
+	}
+	return bumpGeneration(db)
 }
 
 // AddZones creates a new table and adds a list of zones.
@@ -117,14 +148,36 @@ func AddZones(timezone string, zones []Zone) error {
 		return err
 	}
 
-	newTableName := fmt.Sprintf("%s%v", original.TabName, original.TabVer)
-	createZoneTable(newTableName)
+	if err := addZones(db, fmt.Sprintf("%s%v", original.TabName, original.TabVer), zones); err != nil {
+		return err
+	}
+
+	return bumpGeneration(db)
+}
+
+func addZones(conn dbConn, newTableName string, zones []Zone) error {
+	schema := strings.Replace(getZoneSchema(newTableName), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1)
+	if _, err := conn.Exec(schema); err != nil {
+		return err
+	}
+	for column, query := range getZoneMigrations(newTableName) {
+		var count int
+		err := conn.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name=?", newTableName, column).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			if _, err := conn.Exec(query); err != nil {
+				return err
+			}
+		}
+	}
 
 	fields := getZoneCols()
-	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?)",
-		newTableName, fields[1], fields[2], fields[3], fields[4], fields[5])
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		newTableName, fields[1], fields[2], fields[3], fields[4], fields[5], fields[6], fields[7])
 
-	stmt, err := db.Prepare(query)
+	stmt, err := conn.Prepare(query)
 	if err != nil {
 		return err
 	}
@@ -137,7 +190,7 @@ func AddZones(timezone string, zones []Zone) error {
 		} else {
 			dst = 0
 		}
-		_, err := stmt.Exec(zone.Name, zone.Start, zone.End, zone.Offset, dst)
+		_, err := stmt.Exec(zone.Name, zone.Start, zone.End, zone.Offset, dst, zone.IsStd, zone.IsUT)
 		if err != nil {
 			return err
 		}
@@ -167,8 +220,11 @@ func UpdateReplica(replicaTZ, originalTZ string) error {
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(id)
-	return err
+	if _, err = stmt.Exec(id); err != nil {
+		return err
+	}
+
+	return bumpGeneration(db)
 }
 
 // needOriginalID retrieves ID for named origial TZ or creates it.
@@ -5,12 +5,17 @@ import (
 	"fmt"
 	_ "github.com/mattn/go-sqlite3"
 	"strings"
+	"sync"
 )
 
 var (
 	db     *sql.DB
 	dbOpen bool
 	noDB   = fmt.Errorf("tzdb: no connection to db")
+
+	// Readers hold dbLock while using db,
+	// so that it can be swapped safely.
+	dbLock sync.RWMutex
 )
 
 func init() {
@@ -18,9 +23,10 @@ func init() {
 }
 
 func OpenRO(filename string) error {
-	dsn := fmt.Sprintf("file:%s?cache=private&_locking=normal&mode=ro", filename)
+	dbObj, err := openRO(filename)
 
-	dbObj, err := sql.Open("sqlite3", dsn)
+	dbLock.Lock()
+	defer dbLock.Unlock()
 
 	if err != nil {
 		dbOpen = false
@@ -33,37 +39,74 @@ func OpenRO(filename string) error {
 	return nil
 }
 
+func openRO(filename string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?cache=private&_locking=normal&mode=ro", filename)
+	return sql.Open("sqlite3", dsn)
+}
+
 func Open(filename string) error {
 	dsn := fmt.Sprintf("file:%s?cache=shared&mode=rwc&_journal_mode=WAL", filename)
 
 	dbObj, err := sql.Open("sqlite3", dsn)
 
+	dbLock.Lock()
 	if err != nil {
 		dbOpen = false
+		dbLock.Unlock()
 		return err
 	}
 
 	dbOpen = true
 	db = dbObj
+	dbLock.Unlock()
 
 	if !tableExists(originalTable) {
 		createTable(getOriginalSchema())
+	} else {
+		for column, query := range getOriginalMigrations() {
+			if !columnExists(originalTable, column) {
+				createTable(query)
+			}
+		}
 	}
 
 	if !tableExists(replicaTable) {
 		createTable(getReplicaSchema())
 	}
 
+	// the tables of audit entries and overrides are usually empty, and the tables of
+	// countries and of the generation have no autoincrement key, so they are not listed
+	// in sqlite_sequence and tableExists cannot find them
+	createTable(strings.Replace(getAuditSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getOverrideSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getCountrySchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getCountryZoneSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getWindowsSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getMetazoneSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getZoneNamesSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(strings.Replace(getAbbrevSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+	createTable(getAbbrevIndexSchema())
+	createTable(strings.Replace(getGenerationSchema(), "CREATE TABLE", "CREATE TABLE IF NOT EXISTS", 1))
+
 	return nil
 }
 
 func Close() error {
+	stopWatching()
+
+	dbLock.Lock()
+	defer dbLock.Unlock()
+
 	if !dbOpen {
 		return noDB
 	}
 
-	db.Close()
-	return nil
+	dbOpen = false
+	if imageConn != nil {
+		imageConn.Close()
+		imageConn = nil
+	}
+	return db.Close()
 }
 
 func tableExists(tableName string) bool {
@@ -78,22 +121,18 @@ func tableExists(tableName string) bool {
 	return true
 }
 
-func createTable(query string) error {
-	stmt, err := db.Prepare(query)
-	if err != nil {
-		return err
-	}
-	_, err = stmt.Exec()
+func columnExists(tableName, column string) bool {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name='%s';", tableName, column)
+	err := db.QueryRow(query).Scan(&count)
 	if err != nil {
-		return err
+		return false
 	}
 
-	return nil
+	return count > 0
 }
 
-func createZoneTable(tableName string) error {
-	query := getZoneSchema(tableName)
-
+func createTable(query string) error {
 	stmt, err := db.Prepare(query)
 	if err != nil {
 		return err
@@ -2,6 +2,7 @@ package tzdb
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 )
 
@@ -24,6 +25,66 @@ func init() {
 	   stmt.Close()
 	*/
 }
+
+// openTestDB opens a new database in a temporary directory, for tests that
+// replace whole tables, and copies the specified timezones into it from the
+// shared database, which is opened again when the test ends.
+func openTestDB(t *testing.T, timezones ...string) {
+	t.Helper()
+
+	type timezone struct {
+		original Original
+		replicas []string
+		zones    []Zone
+	}
+	copies := make([]timezone, 0, len(timezones))
+	for _, name := range timezones {
+		original, err := GetOriginalByName(name)
+		if err != nil {
+			t.Fatalf("cannot find %q in shared database: %s", name, err)
+		}
+		replicas, err := getReplicasOf(original.ID)
+		if err != nil {
+			t.Fatalf("cannot read replicas of %q: %s", name, err)
+		}
+		zones, err := GetZones(name)
+		if err != nil {
+			zones = nil
+		}
+		copies = append(copies, timezone{*original, replicas, zones})
+	}
+
+	dir := t.TempDir()
+	Close()
+	t.Cleanup(func() {
+		Close()
+		Open("../tsdb.sqlite")
+	})
+	if err := Open(filepath.Join(dir, "tsdb.sqlite")); err != nil {
+		t.Fatalf("cannot open temporary database: %s", err)
+	}
+
+	for _, tz := range copies {
+		if _, err := AddOriginal(tz.original.Name); err != nil {
+			t.Fatalf("cannot add %q: %s", tz.original.Name, err)
+		}
+		if err := AddReplicas(tz.replicas, tz.original.Name); err != nil {
+			t.Fatalf("cannot add replicas of %q: %s", tz.original.Name, err)
+		}
+		if len(tz.zones) == 0 {
+			tz.original.TabVer = 0
+		}
+		if err := UpdateOriginal(&tz.original); err != nil {
+			t.Fatalf("cannot update %q: %s", tz.original.Name, err)
+		}
+		if len(tz.zones) != 0 {
+			if err := AddZones(tz.original.Name, tz.zones); err != nil {
+				t.Fatalf("cannot add zones of %q: %s", tz.original.Name, err)
+			}
+		}
+	}
+}
+
 func TestGetZones(t *testing.T) {
 	zones, err := GetZones("Europe/Athens")
 	if err != nil {
@@ -59,7 +120,7 @@ func TestUpdateOriginal(t *testing.T) {
 	for _, original := range originals {
 		err := UpdateOriginal(&original)
 		if err != nil {
-			fmt.Printf("Failed to update data for original %q\n", original)
+			fmt.Printf("Failed to update data for original %q\n", original.Name)
 			t.Errorf("%s", err)
 		} else {
 			fmt.Printf("Updated data for original %q\n", original.Name)
@@ -67,6 +128,34 @@ func TestUpdateOriginal(t *testing.T) {
 	}
 }
 
+func TestClippedOriginal(t *testing.T) {
+	if _, err := AddOriginal("Irakleio"); err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	for _, since := range []int64{0, -2208988800} {
+		original := Original{Name: "Irakleio", DZone: "kalokairi", DOffset: 312, TZDVer: "2020a", Clipped: true, Since: since}
+		if err := UpdateOriginal(&original); err != nil {
+			t.Fatalf("%s", err)
+		}
+		stored, err := GetOriginalByName("Irakleio")
+		if err != nil {
+			t.Fatalf("%s", err)
+		}
+		if !stored.Clipped || stored.Since != since {
+			t.Errorf("stored clipping %v at %d, want at %d", stored.Clipped, stored.Since, since)
+		}
+	}
+
+	original := Original{Name: "Irakleio", DZone: "kalokairi", DOffset: 312, TZDVer: "2020a"}
+	if err := UpdateOriginal(&original); err != nil {
+		t.Fatalf("%s", err)
+	}
+	if stored, err := GetOriginalByName("Irakleio"); err != nil || stored.Clipped {
+		t.Errorf("stored clipping of unclipped original: %+v, %v", stored, err)
+	}
+}
+
 func TestAddZones(t *testing.T) {
 	var original = "Lamia"
 	var zones = []Zone{
@@ -0,0 +1,128 @@
+package tzdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// zoneType is a local time type, as defined in RFC 8536.
+type zoneType struct {
+	offset int32
+	isDST  bool
+	abbrev string
+	isStd  bool
+	isUT   bool
+}
+
+// encodeTZif serializes zones and a footer (TZ string) in TZif version 2
+// format, as defined in RFC 8536. Zones should be sorted by start time.
+// The type of the first zone is in effect before the first transition,
+// unless since is not math.MinInt64: local time before since is unknown
+// then, as in the output of zic -r, so zones before it are left out, the
+// zone in effect at since starts at it and a "-00" type with offset 0 is
+// in effect before. The data block of version 1 is kept minimal, as
+// readers of version 2 and later skip it anyway. The standard/wall and
+// UT/local indicators are only written if any type has them set. An
+// error is returned if the zones have more types or abbreviations than
+// TZif can hold.
+func encodeTZif(zones []Zone, footer string, since int64) ([]byte, error) {
+	types := make([]zoneType, 0, 8)
+	typeIndex := make(map[zoneType]int, 8)
+	transTimes := make([]int64, 0, len(zones))
+	transTypes := make([]byte, 0, len(zones))
+	indicators := false
+
+	if since != math.MinInt64 {
+		unknown := zoneType{abbrev: "-00"}
+		typeIndex[unknown] = 0
+		types = append(types, unknown)
+	}
+
+	for _, zone := range zones {
+		start := zone.Start
+		if start < since {
+			if zone.End != -1 && zone.End < since {
+				continue
+			}
+			start = since
+		}
+		t := zoneType{offset: int32(zone.Offset), isDST: zone.IsDST, abbrev: zone.Name,
+			isStd: zone.IsStd || zone.IsUT, isUT: zone.IsUT}
+		indicators = indicators || t.isStd
+		index, known := typeIndex[t]
+		if !known {
+			if len(types) == 256 {
+				return nil, fmt.Errorf("tzdb: more than 256 local time types, at zone %s starting at %d", zone.Name, start)
+			}
+			index = len(types)
+			typeIndex[t] = index
+			types = append(types, t)
+		}
+		if start != math.MinInt64 {
+			transTimes = append(transTimes, start)
+			transTypes = append(transTypes, byte(index))
+		}
+	}
+	if len(types) == 0 {
+		types = append(types, zoneType{abbrev: "UTC"})
+	}
+
+	// abbreviations are stored once, each terminated by NUL
+	chars := make([]byte, 0, 4*len(types))
+	charIndex := make(map[string]int, len(types))
+	for _, t := range types {
+		if _, known := charIndex[t.abbrev]; !known {
+			if len(chars) > math.MaxUint8 {
+				// indices of abbreviations are single bytes
+				return nil, fmt.Errorf("tzdb: abbreviations do not fit in %d bytes, at %s", math.MaxUint8+1, t.abbrev)
+			}
+			charIndex[t.abbrev] = len(chars)
+			chars = append(append(chars, t.abbrev...), 0)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	put := func(v interface{}) {
+		binary.Write(buf, binary.BigEndian, v)
+	}
+	header := func(indcnt, timecnt, typecnt, charcnt int) {
+		buf.WriteString("TZif2")
+		buf.Write(make([]byte, 15))
+		// isutcnt, isstdcnt, leapcnt, timecnt, typecnt, charcnt
+		for _, count := range []int{indcnt, indcnt, 0, timecnt, typecnt, charcnt} {
+			put(uint32(count))
+		}
+	}
+
+	// version 1: no transitions and a single type with an empty abbreviation
+	header(0, 0, 1, 1)
+	buf.Write([]byte{0, 0, 0, 0, 0, 0, 0})
+
+	// version 2
+	indcnt := 0
+	if indicators {
+		indcnt = len(types)
+	}
+	header(indcnt, len(transTimes), len(types), len(chars))
+	put(transTimes)
+	buf.Write(transTypes)
+	for _, t := range types {
+		put(t.offset)
+		put(t.isDST)
+		put(uint8(charIndex[t.abbrev]))
+	}
+	buf.Write(chars)
+	if indicators {
+		for _, t := range types {
+			put(t.isStd)
+		}
+		for _, t := range types {
+			put(t.isUT)
+		}
+	}
+
+	buf.WriteString("\n" + footer + "\n")
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,240 @@
+package tzdb
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultTerritory is the territory of the default mapping of a Windows ID.
+const defaultTerritory = "001"
+
+// windowsZonesXML is the part of windowsZones.xml of CLDR that is needed.
+type windowsZonesXML struct {
+	MapTimezones struct {
+		OtherVersion string `xml:"otherVersion,attr"`
+		TypeVersion  string `xml:"typeVersion,attr"`
+		MapZones     []struct {
+			Other     string `xml:"other,attr"`
+			Territory string `xml:"territory,attr"`
+			Type      string `xml:"type,attr"`
+		} `xml:"mapZone"`
+	} `xml:"windowsZones>mapTimezones"`
+}
+
+// ReadWindowsZones reads the mapping of Windows timezone IDs from
+// windowsZones.xml of CLDR. The version of the mapping is returned as well.
+func ReadWindowsZones(r io.Reader) (zones []WindowsZone, version string, err error) {
+	var doc windowsZonesXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, "", fmt.Errorf("tzdb: cannot parse windowsZones.xml: %s", err)
+	}
+
+	mapping := doc.MapTimezones
+	if len(mapping.MapZones) == 0 {
+		return nil, "", errors.New("tzdb: no mapping of Windows IDs found")
+	}
+
+	zones = make([]WindowsZone, 0, len(mapping.MapZones))
+	for _, m := range mapping.MapZones {
+		timezones := strings.Fields(m.Type)
+		if m.Other == "" || m.Territory == "" || len(timezones) == 0 {
+			return nil, "", fmt.Errorf("tzdb: incomplete mapping of Windows ID %q", m.Other)
+		}
+		zones = append(zones, WindowsZone{WindowsID: m.Other, Territory: m.Territory, Timezones: timezones})
+	}
+
+	version = mapping.TypeVersion
+	if mapping.OtherVersion != "" {
+		version += " (Windows " + mapping.OtherVersion + ")"
+	}
+	return zones, version, nil
+}
+
+// SetWindowsZones replaces the stored mapping of Windows timezone IDs.
+func SetWindowsZones(zones []WindowsZone) error {
+	if !dbOpen {
+		return noDB
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", windowsTable)); err != nil {
+		return err
+	}
+
+	fields := getWindowsCols()
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES(?, ?, ?)", windowsTable, fields[1], fields[2], fields[3])
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, zone := range zones {
+		if _, err := stmt.Exec(zone.WindowsID, zone.Territory, strings.Join(zone.Timezones, " ")); err != nil {
+			return err
+		}
+	}
+
+	if err := bumpGeneration(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetWindowsZones retrieves the stored mapping of Windows timezone IDs,
+// in the order of windowsZones.xml.
+func GetWindowsZones() ([]WindowsZone, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	return getWindowsZones()
+}
+
+// FromWindowsID returns the timezone that corresponds to a Windows timezone ID
+// (e.g. "GTB Standard Time") in a territory, given as an ISO 3166 alpha-2 code.
+// If the territory is empty or has no mapping of its own, the default mapping
+// of the Windows ID is used.
+func FromWindowsID(id, territory string) (string, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return "", noDB
+	}
+
+	zones, err := getWindowsZones()
+	if err != nil {
+		return "", err
+	}
+
+	territory = strings.ToUpper(territory)
+	timezone := ""
+	for _, zone := range zones {
+		if !strings.EqualFold(zone.WindowsID, id) {
+			continue
+		}
+		if zone.Territory == territory {
+			return zone.Timezones[0], nil
+		}
+		if zone.Territory == defaultTerritory {
+			timezone = zone.Timezones[0]
+		}
+	}
+
+	if timezone == "" {
+		return "", fmt.Errorf("tzdb: unknown Windows timezone ID %q", id)
+	}
+	return timezone, nil
+}
+
+// ToWindowsID returns the Windows timezone ID that corresponds to a timezone.
+// Timezones not listed in windowsZones.xml get the Windows ID of the original
+// they link to, or of another replica of it, since CLDR may list another name.
+func ToWindowsID(timezone string) (string, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return "", noDB
+	}
+
+	zones, err := getWindowsZones()
+	if err != nil {
+		return "", err
+	}
+
+	windowsIDs := make(map[string]string)
+	for _, zone := range zones {
+		for _, name := range zone.Timezones {
+			if _, ok := windowsIDs[name]; !ok || zone.Territory == defaultTerritory {
+				windowsIDs[name] = zone.WindowsID
+			}
+		}
+	}
+
+	if id, ok := windowsIDs[timezone]; ok {
+		return id, nil
+	}
+
+	original, err := getOriginal(timezone)
+	if err != nil {
+		return "", err
+	}
+	if id, ok := windowsIDs[original.Name]; ok {
+		return id, nil
+	}
+
+	replicas, err := getReplicasOf(original.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, replica := range replicas {
+		if id, ok := windowsIDs[replica]; ok {
+			return id, nil
+		}
+	}
+
+	return "", fmt.Errorf("tzdb: no Windows timezone ID for %q", timezone)
+}
+
+// getWindowsZones retrieves the stored mapping of Windows timezone IDs.
+// Databases without a table of Windows IDs have none.
+func getWindowsZones() ([]WindowsZone, error) {
+	zones := make([]WindowsZone, 0, 500)
+	if !tableDefined(windowsTable) {
+		return zones, nil
+	}
+
+	fields := getWindowsCols()
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s ORDER BY %s", windowsTable, fields[0]))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var zone WindowsZone
+		var timezones string
+		if err := rows.Scan(&zone.ID, &zone.WindowsID, &zone.Territory, &timezones); err != nil {
+			return nil, err
+		}
+		zone.Timezones = strings.Fields(timezones)
+		zones = append(zones, zone)
+	}
+
+	return zones, rows.Err()
+}
+
+// getReplicasOf retrieves the names of the replicas of an original.
+func getReplicasOf(originalID int64) ([]string, error) {
+	fields := getReplicaCols()
+	rows, err := db.Query(fmt.Sprintf("SELECT %s FROM %s WHERE %s=? ORDER BY %s", fields[1], replicaTable, fields[2], fields[1]), originalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0, 5)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
@@ -0,0 +1,71 @@
+package tzdb
+
+import (
+	"strings"
+	"testing"
+)
+
+const testWindowsZones = `<?xml version="1.0" encoding="UTF-8" ?>
+<!DOCTYPE supplementalData SYSTEM "../../common/dtd/ldmlSupplemental.dtd">
+<supplementalData>
+	<version number="$Revision$"/>
+	<windowsZones>
+		<mapTimezones otherVersion="7e11a00" typeVersion="2021a">
+			<!-- (UTC+02:00) Athens, Bucharest -->
+			<mapZone other="GTB Standard Time" territory="001" type="Europe/Bucharest"/>
+			<mapZone other="GTB Standard Time" territory="CY" type="Asia/Nicosia Asia/Famagusta Europe/Nicosia"/>
+			<mapZone other="GTB Standard Time" territory="GR" type="Europe/Athens"/>
+			<mapZone other="GTB Standard Time" territory="RO" type="Europe/Bucharest"/>
+			<!-- (UTC) Coordinated Universal Time -->
+			<mapZone other="UTC" territory="001" type="Etc/UTC"/>
+			<mapZone other="UTC" territory="ZZ" type="Etc/UTC Etc/GMT"/>
+		</mapTimezones>
+	</windowsZones>
+</supplementalData>
+`
+
+func TestWindowsZones(t *testing.T) {
+	openTestDB(t, "Europe/Athens", "Asia/Famagusta", "Etc/UTC")
+
+	zones, version, err := ReadWindowsZones(strings.NewReader(testWindowsZones))
+	if err != nil {
+		t.Fatalf("ReadWindowsZones failed: %s", err)
+	}
+	if len(zones) != 6 || version != "2021a (Windows 7e11a00)" || len(zones[1].Timezones) != 3 {
+		t.Fatalf("ReadWindowsZones = %v, %q", zones, version)
+	}
+
+	if err := SetWindowsZones(zones); err != nil {
+		t.Fatalf("SetWindowsZones failed: %s", err)
+	}
+
+	for _, test := range []struct {
+		id, territory, timezone string
+	}{
+		{"GTB Standard Time", "GR", "Europe/Athens"},
+		{"GTB Standard Time", "cy", "Asia/Nicosia"},
+		{"GTB Standard Time", "", "Europe/Bucharest"},
+		{"GTB Standard Time", "FR", "Europe/Bucharest"},
+		{"UTC", "", "Etc/UTC"},
+	} {
+		if timezone, err := FromWindowsID(test.id, test.territory); err != nil || timezone != test.timezone {
+			t.Errorf("FromWindowsID(%q, %q) = %q, %v, want %q", test.id, test.territory, timezone, err, test.timezone)
+		}
+	}
+	if _, err := FromWindowsID("Atlantis Standard Time", ""); err == nil {
+		t.Errorf("FromWindowsID of unknown ID did not fail")
+	}
+
+	for _, test := range []struct {
+		timezone, id string
+	}{
+		{"Europe/Athens", "GTB Standard Time"},
+		{"Asia/Famagusta", "GTB Standard Time"},
+		{"Etc/UTC", "UTC"},
+		{"Zulu", "UTC"}, // not listed, but links to Etc/UTC
+	} {
+		if id, err := ToWindowsID(test.timezone); err != nil || id != test.id {
+			t.Errorf("ToWindowsID(%q) = %q, %v, want %q", test.timezone, id, err, test.id)
+		}
+	}
+}
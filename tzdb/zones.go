@@ -0,0 +1,145 @@
+package tzdb
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LookupZone returns the zone in effect at the specified instant,
+// expressed in seconds since January 1, 1970 UTC. Zones should be
+// sorted by start time, as returned by GetZones. The boolean result
+// is false if the instant is not covered by any of the zones.
+func LookupZone(zones []Zone, sec int64) (Zone, bool) {
+	// find first zone that starts after sec
+	i := sort.Search(len(zones), func(i int) bool {
+		return zones[i].Start > sec
+	})
+	if i == 0 {
+		return Zone{}, false
+	}
+
+	zone := zones[i-1]
+	if zone.End != -1 && sec > zone.End {
+		return Zone{}, false
+	}
+
+	return zone, true
+}
+
+// Lookup returns the zone in effect for specified timezone at the
+// specified instant, expressed in seconds since January 1, 1970 UTC.
+// Timezones without any zones get their default zone, which extends
+// from the beginning to the end of time.
+func Lookup(timezone string, sec int64) (Zone, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return Zone{}, noDB
+	}
+
+	zones, err := getEffectiveZones(timezone)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	zone, ok := LookupZone(zones, sec)
+	if !ok {
+		return Zone{}, fmt.Errorf("tzdb: no zone of %q in effect at %d", timezone, sec)
+	}
+
+	return zone, nil
+}
+
+// GetEffectiveZones retrieves the zones of specified timezone, like GetZones.
+// Timezones without any zones get their default zone, which extends from the
+// beginning to the end of time.
+func GetEffectiveZones(timezone string) ([]Zone, error) {
+	dbLock.RLock()
+	defer dbLock.RUnlock()
+
+	if !dbOpen {
+		return nil, noDB
+	}
+
+	return getEffectiveZones(timezone)
+}
+
+func getEffectiveZones(timezone string) ([]Zone, error) {
+	original, err := getOriginal(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return effectiveZones(original)
+}
+
+// effectiveZones retrieves the zones of an original, as readers see them.
+// Zones of originals sharing the table of another one since an instant
+// are left out before it, as they are those of the other original.
+func effectiveZones(original *Original) ([]Zone, error) {
+	zones, err := getActiveZones(original)
+	if err != nil {
+		// originals without any transitions never get a table of zones
+		if original.TabVer != 0 {
+			return nil, err
+		}
+		return []Zone{{Name: original.DZone, Offset: original.DOffset, Start: math.MinInt64, End: -1}}, nil
+	}
+
+	if original.Shared {
+		zones = zonesSince(zones, original.SharedSince)
+	}
+	return zones, nil
+}
+
+// zonesSince returns the zones in effect since an instant. The first
+// of them is considered to start at that instant, if earlier.
+func zonesSince(zones []Zone, since int64) []Zone {
+	result := make([]Zone, 0, len(zones))
+	for _, zone := range zones {
+		if zone.End != -1 && zone.End < since {
+			continue
+		}
+		if zone.Start < since {
+			zone.Start = since
+		}
+		result = append(result, zone)
+	}
+	return result
+}
+
+// ZonesBetween returns the zones that are in effect at any
+// instant of the range [from, to]. Zones should be sorted by
+// start time, as returned by GetZones.
+func ZonesBetween(zones []Zone, from, to int64) []Zone {
+	between := make([]Zone, 0)
+	for _, zone := range zones {
+		if zone.Start > to {
+			break
+		}
+		if zone.End != -1 && zone.End < from {
+			continue
+		}
+		between = append(between, zone)
+	}
+	return between
+}
+
+// ResolveLocal converts a local (wall clock) time, expressed in seconds
+// since January 1, 1970, to the UTC instants that it corresponds to.
+// Local times skipped by a transition (gap) have no instants, while
+// local times repeated by a transition (overlap) have two instants.
+// The zone in effect at each instant is returned along with it.
+func ResolveLocal(zones []Zone, local int64) (instants []int64, inEffect []Zone) {
+	for _, zone := range zones {
+		utc := local - zone.Offset
+		if utc < zone.Start || (zone.End != -1 && utc > zone.End) {
+			continue
+		}
+		instants = append(instants, utc)
+		inEffect = append(inEffect, zone)
+	}
+	return instants, inEffect
+}
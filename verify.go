@@ -0,0 +1,195 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdata"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// mismatch describes a disagreement between the database and one
+// of the sources it is checked against, at a specific instant.
+type mismatch struct {
+	when            int64
+	dbName          string
+	dbOffset        int64
+	srcName, oracle string
+}
+
+// runVerify cross-checks every timezone stored in the database against
+// the zoneinfo source it was generated from and against the time package.
+// The returned value is the exit status.
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to verify")
+	samples := flags.Int("samples", 100, "instants to sample for each timezone, besides stored transitions")
+	flags.Parse(args)
+
+	if err := tzdb.OpenRO(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 2
+	}
+	defer tzdb.Close()
+
+	names, err := storedTimezones()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read timezones from %q: %s\n", *filename, err)
+		return 2
+	}
+
+	failed := 0
+	for _, name := range names {
+		mismatches, err := verifyTimezone(name, *samples)
+		if err != nil {
+			fmt.Printf("%s\n    cannot verify: %s\n", name, err)
+			failed++
+			continue
+		}
+		if len(mismatches) == 0 {
+			continue
+		}
+
+		failed++
+		fmt.Printf("%s\n", name)
+		for _, m := range mismatches {
+			fmt.Printf("    %s  db: %s %+d  tzdata: %s  time: %s\n",
+				time.Unix(m.when, 0).UTC().Format(time.RFC3339),
+				m.dbName, m.dbOffset, m.srcName, m.oracle)
+		}
+	}
+
+	fmt.Printf("\n%d timezones verified, %d failed\n", len(names), failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// storedTimezones returns the sorted names of all originals and replicas.
+func storedTimezones() ([]string, error) {
+	originals, err := tzdb.GetOriginals()
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, err := tzdb.GetReplicas()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(replicas))
+	names := make([]string, 0, len(replicas))
+	for _, original := range originals {
+		seen[original.Name] = true
+		names = append(names, original.Name)
+	}
+	for _, replica := range replicas {
+		if !seen[replica.Name] {
+			seen[replica.Name] = true
+			names = append(names, replica.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// futureYears is the span after the last stored transition that is sampled
+// by verify, to cover the zones given by the footer of each timezone.
+const futureYears = 5
+
+// verifyTimezone compares stored zones of a timezone with the zones
+// reported by tzdata and by time.LoadLocation. Instants are taken
+// from the start and end of each stored zone, plus a number of
+// samples evenly spread between the first zone and a few years
+// after the last, and a sample per month in these last years.
+// After the start of the last zone, the stored footer is compared.
+// Instants before the zones of a timezone were compared when sharing
+// the table of another one are skipped, since the zones before that
+// are those of the other timezone.
+func verifyTimezone(name string, samples int) ([]mismatch, error) {
+	data, err := tzdata.GetData(name)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := tzdb.GetOriginal(name)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := tzdb.GetZones(name)
+	if err != nil {
+		// without zones, only the default zone can be checked,
+		// which is in effect for ever, as in tzdb.Lookup
+		zones = []tzdb.Zone{{Name: original.DZone, Offset: original.DOffset, Start: math.MinInt64, End: -1}}
+	}
+
+	// the stored footer only takes effect through LoadLocation
+	stored, err := tzdb.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	instants := make([]int64, 0, 2*len(zones)+samples+12*futureYears)
+	for _, zone := range zones {
+		if zone.Start != math.MinInt64 {
+			instants = append(instants, zone.Start)
+		}
+		if zone.End != -1 {
+			instants = append(instants, zone.End)
+		}
+	}
+	// zones in effect since the beginning of time are sampled
+	// since 1900, and a single one of them up to now
+	first, lastStart := zones[0].Start, zones[len(zones)-1].Start
+	if lastStart == math.MinInt64 {
+		lastStart = time.Now().Unix()
+	}
+	if first == math.MinInt64 {
+		first = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	}
+	future := time.Unix(lastStart, 0).UTC().AddDate(futureYears, 0, 0).Unix()
+	for i := 0; i < samples && future > first; i++ {
+		instants = append(instants, first+(future-first)/int64(samples)*int64(i))
+	}
+	for month := 1; month <= 12*futureYears; month++ {
+		instants = append(instants, time.Unix(lastStart, 0).UTC().AddDate(0, month, 0).Unix())
+	}
+
+	mismatches := make([]mismatch, 0)
+	for _, sec := range instants {
+		zone, ok := tzdb.LookupZone(zones, sec)
+		if !ok || (original.Shared && sec < original.SharedSince) {
+			continue
+		}
+		if sec > lastStart {
+			zoneName, zoneOffset := time.Unix(sec, 0).In(stored).Zone()
+			zone = tzdb.Zone{Name: zoneName, Offset: int64(zoneOffset)}
+		}
+		srcName, srcOffset, _, _ := data.Lookup(sec)
+		goName, goOffset := time.Unix(sec, 0).In(location).Zone()
+
+		if zone.Name == srcName && zone.Offset == int64(srcOffset) &&
+			zone.Name == goName && zone.Offset == int64(goOffset) {
+			continue
+		}
+		mismatches = append(mismatches, mismatch{
+			when:     sec,
+			dbName:   zone.Name,
+			dbOffset: zone.Offset,
+			srcName:  fmt.Sprintf("%s %+d", srcName, srcOffset),
+			oracle:   fmt.Sprintf("%s %+d", goName, goOffset)})
+	}
+
+	return mismatches, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdata"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"log"
+	"os"
+	"time"
+)
+
+// runWatch keeps a database up to date with the zoneinfo source, running
+// the update procedure whenever the version in tzdata.zi changes.
+// It only returns if watching cannot start. The returned value is the exit status.
+func runWatch(args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to keep up to date")
+	output := flags.String("output", "plain", "progress output: auto, tty, plain or json")
+	summaryFile := flags.String("summary", "", "also write JSON summary of each run to file")
+	zoneinfo := flags.String("zoneinfo", tzdata.DefaultSourcePath, "directory of zoneinfo source")
+	interval := flags.Duration("interval", time.Minute, "interval of checks, if changes cannot be notified")
+	settle := flags.Duration("settle", 10*time.Second, "time to wait after a change, for the source to settle")
+	overridesFile := flags.String("overrides", "", "file of overrides to apply on top of tzdata")
+	since := flags.String("since", "", "drop zones before this time (e.g. 1900-01-01T00:00:00Z)")
+	policy := policyFlags(flags)
+	dedup := dedupFlags(flags)
+	flags.Parse(args)
+
+	tzdata.SetSourcePath(*zoneinfo)
+
+	rep, err := newReporter(*output, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 2
+	}
+
+	if _, err := tzdata.GetVersion(); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read zoneinfo source: %s\n", err)
+		return 1
+	}
+
+	wait := pollSource(*interval)
+	if changes, err := notifyChanges(tzdata.SourcePath()); err != nil {
+		log.Printf("cannot watch %q (%s), checking every %s", tzdata.SourcePath(), err, *interval)
+	} else {
+		wait = notifiedSource(changes, *settle, wait)
+	}
+
+	// there is no need to run at start-up if the database is up to date
+	last := storedVersion(*filename)
+	log.Printf("watching %q for %q (tzdata %q)", tzdata.SourcePath(), *filename, last)
+
+	for ; ; wait() {
+		version, err := tzdata.GetVersion()
+		if err != nil {
+			log.Printf("cannot read version of zoneinfo source: %s", err)
+			continue
+		}
+		if version == last {
+			continue
+		}
+
+		// A failed run is not repeated until the version changes again,
+		// since the safety policy would most probably reject it again.
+		log.Printf("tzdata changed from %q to %q, updating %q", last, version, *filename)
+		last = version
+
+		summary := generate(*filename, *policy, *overridesFile, *since, *dedup, rep)
+		rep.summary(summary)
+		log.Printf("run %s: %d updated, %d unchanged, %d skipped in %.1fs",
+			summary.Status, summary.Updated, summary.Unchanged, summary.Skipped, summary.Duration)
+
+		if *summaryFile != "" {
+			if err := writeSummary(*summaryFile, summary); err != nil {
+				log.Printf("cannot write summary: %s", err)
+			}
+		}
+	}
+}
+
+// storedVersion returns the version of tzdata in the specified
+// database or an empty string if it cannot be read.
+func storedVersion(filename string) string {
+	if err := openDB(filename); err != nil {
+		return ""
+	}
+	defer tzdb.Close()
+
+	version, err := tzdb.GetTZDataVersion()
+	if err != nil {
+		return ""
+	}
+	return version
+}
+
+// pollSource returns a function that waits for the next check of the source.
+func pollSource(interval time.Duration) func() {
+	return func() {
+		time.Sleep(interval)
+	}
+}
+
+// notifiedSource returns a function that waits for a notification of
+// change and then for the source to settle, since package managers
+// replace files one after the other. If notifications stop, it falls
+// back to the specified function.
+func notifiedSource(changes <-chan struct{}, settle time.Duration, fallback func()) func() {
+	return func() {
+		if changes == nil {
+			fallback()
+			return
+		}
+
+		if _, ok := <-changes; !ok {
+			log.Printf("notifications of changes stopped, falling back to periodic checks")
+			changes = nil
+			return
+		}
+
+		for timer := time.NewTimer(settle); ; {
+			select {
+			case <-changes:
+				timer.Reset(settle)
+			case <-timer.C:
+				return
+			}
+		}
+	}
+}
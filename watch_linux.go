@@ -0,0 +1,65 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// notifyChanges reports changes of tzdata.zi in the specified directory,
+// using inotify. The directory is watched rather than the file itself,
+// since updates usually replace the file instead of modifying it.
+// The returned channel is closed if notifications cannot be read.
+func notifyChanges(dir string) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+
+	mask := uint32(syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE | syscall.IN_DELETE)
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	changes := make(chan struct{}, 1)
+	go func() {
+		defer syscall.Close(fd)
+		defer close(changes)
+
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := syscall.Read(fd, buf)
+			if err == syscall.EINTR {
+				continue
+			}
+			if err != nil || n <= 0 {
+				log.Printf("cannot read notifications: %v", err)
+				return
+			}
+
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+				start := offset + syscall.SizeofInotifyEvent
+				offset = start + int(event.Len)
+				if offset > n {
+					break
+				}
+
+				name := strings.TrimRight(string(buf[start:offset]), "\x00")
+				if name != "tzdata.zi" {
+					continue
+				}
+
+				// a pending notification is as good as a new one
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changes, nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+// notifyChanges is only implemented on Linux.
+// Elsewhere, the source is checked periodically.
+func notifyChanges(dir string) (<-chan struct{}, error) {
+	return nil, errors.New("notification of changes not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pvar/ts-db-generator/tzdb"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runWindows imports the mapping of Windows timezone IDs from windowsZones.xml
+// of CLDR, replacing the stored one. The returned value is the exit status.
+func runWindows(args []string) int {
+	flags := flag.NewFlagSet("windows", flag.ExitOnError)
+	filename := flags.String("db", dbfile, "database file to update")
+	dryRun := flags.Bool("n", false, "only check the mapping")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: ts-db-generator windows [-db file] [-n] {windowsZones.xml}\n")
+		return 2
+	}
+	source := flags.Arg(0)
+
+	file, err := os.Open(source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read %q: %s\n", source, err)
+		return 1
+	}
+	zones, version, err := tzdb.ReadWindowsZones(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read %q: %s\n", source, err)
+		return 1
+	}
+
+	open := tzdb.Open
+	if *dryRun {
+		open = openDB
+	}
+	if err := open(*filename); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot open %q: %s\n", *filename, err)
+		return 1
+	}
+	defer tzdb.Close()
+
+	// CLDR may be newer or older than tzdata, so unknown
+	// timezones are reported, but do not abort the import
+	replicas, err := tzdb.GetReplicas()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot read replicas: %s\n", err)
+		return 1
+	}
+	known := make(map[string]bool, len(replicas))
+	for _, replica := range replicas {
+		known[replica.Name] = true
+	}
+	windowsIDs := make(map[string]bool)
+	for _, zone := range zones {
+		windowsIDs[zone.WindowsID] = true
+		for _, timezone := range zone.Timezones {
+			if !known[timezone] {
+				fmt.Printf("%-32s unknown timezone, mapped from %q (%s)\n", timezone, zone.WindowsID, zone.Territory)
+			}
+		}
+	}
+
+	detail := fmt.Sprintf("%d Windows IDs, %d mappings, CLDR %s", len(windowsIDs), len(zones), version)
+	if *dryRun {
+		fmt.Printf("\n%s, nothing imported\n", detail)
+		return 0
+	}
+
+	if err := tzdb.SetWindowsZones(zones); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot store mapping: %s\n", err)
+		return 1
+	}
+
+	if abs, err := filepath.Abs(source); err == nil {
+		source = abs
+	}
+	audit := tzdb.AuditEntry{Time: time.Now().Unix(), Action: "windows", Source: source, Detail: detail}
+	if err := tzdb.AddAudit(audit); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot record audit entry: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("\n%s imported\n", detail)
+	return 0
+}